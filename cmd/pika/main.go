@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+// Command pika provides tooling for pika databases.
+//
+// Usage:
+//
+//	pika migrate [-dsn DSN] [-dir DIR] up|down|to VERSION|status
+//
+// The DSN defaults to the DATABASE_URL environment variable.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"go.ciq.dev/pika"
+	"go.ciq.dev/pika/migrate"
+)
+
+// Static errors for err113 compliance
+var (
+	errMissingDSN     = errors.New("missing -dsn or DATABASE_URL")
+	errUnknownCommand = errors.New("unknown command")
+)
+
+const usage = `Usage: pika migrate [-dsn DSN] [-dir DIR] <command>
+
+Commands:
+  up          apply all pending migrations
+  down        revert the last applied migration
+  to VERSION  apply or revert migrations until VERSION is the last applied migration
+  status      show the state of all migrations
+`
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "migrate" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	err := runMigrate(context.Background(), os.Args[2:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "pika: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runMigrate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dsn := flags.String("dsn", os.Getenv("DATABASE_URL"), "database connection string")
+	dir := flags.String("dir", "migrations", "directory containing the migrations")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	command := flags.Arg(0)
+	var version int64
+	switch {
+	case *dsn == "":
+		return errMissingDSN
+	case command == "to" && flags.NArg() == 2:
+		var err error
+		version, err = strconv.ParseInt(flags.Arg(1), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", flags.Arg(1), err)
+		}
+	case command == "to" || flags.NArg() != 1:
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	psql, err := pika.NewPostgreSQL(*dsn)
+	if err != nil {
+		return err
+	}
+	defer psql.Close()
+
+	m, err := migrate.New(psql, os.DirFS(*dir))
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx)
+	case "to":
+		return m.To(ctx, version)
+	case "status":
+		return printStatus(ctx, m)
+	default:
+		return fmt.Errorf("%w: %s", errUnknownCommand, command)
+	}
+}
+
+func printStatus(ctx context.Context, m *migrate.Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state := "pending"
+		appliedAt := ""
+		switch {
+		case status.Missing:
+			state = "missing"
+		case status.Modified:
+			state = "modified"
+		case status.Applied:
+			state = "applied"
+		}
+		if status.Applied {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+// Package grpcsvc implements the standard methods of AIP-131 to AIP-135
+// for gRPC services that store a resource as a pika model.
+//
+// The handlers convert between models and messages with a Converter,
+// and return errors as gRPC statuses, see Status. For example:
+//
+//	func (s *server) ListArticles(ctx context.Context, req *pb.ListArticlesRequest) (*pb.ListArticlesResponse, error) {
+//		res, err := grpcsvc.ListHandler(ctx, pika.Q[Article](s.psql), req, s.options, s.converter)
+//		if err != nil {
+//			return nil, err
+//		}
+//
+//		return &pb.ListArticlesResponse{
+//			Articles:      res.Items,
+//			NextPageToken: res.NextPageToken,
+//			TotalSize:     res.TotalSize,
+//		}, nil
+//	}
+package grpcsvc
+
+import (
+	"context"
+	"math"
+
+	"go.ciq.dev/pika"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Converter converts between a model T and a message M
+type Converter[T any, M proto.Message] struct {
+	// ToProto converts a model to a new message
+	ToProto func(*T) (M, error)
+	// FromProto converts a message to a new model
+	FromProto func(M) (*T, error)
+}
+
+// ProtoConverter returns a Converter using pika.ToProtoWithOpts and pika.FromProtoWithOpts.
+// Fields are mapped to columns the same way as in ProtoReflect.
+func ProtoConverter[T any, M proto.Message](opts pika.ProtoReflectOptions) Converter[T, M] {
+	return Converter[T, M]{
+		ToProto: func(x *T) (M, error) {
+			// The type of a nil message can still create new messages
+			var msg M
+			msg = msg.ProtoReflect().Type().New().Interface().(M)
+
+			err := pika.ToProtoWithOpts(x, msg, opts)
+			if err != nil {
+				var zero M
+				return zero, err
+			}
+
+			return msg, nil
+		},
+		FromProto: func(msg M) (*T, error) {
+			return pika.FromProtoWithOpts[T](msg, opts)
+		},
+	}
+}
+
+// ListResult is a page of messages returned by ListHandler
+type ListResult[M proto.Message] struct {
+	// Items are the messages of the page
+	Items []M
+	// NextPageToken is the token of the next page, empty on the last page
+	NextPageToken string
+	// TotalSize is the number of rows matching the filter across all pages
+	TotalSize int32
+}
+
+// ListHandler implements an AIP-132 List method.
+// The request is paginated with GetPage, using the filter options usually created with pika.ProtoReflect.
+// Filters applied to qs are kept, for example to list the children of a parent.
+func ListHandler[T any, M proto.Message](ctx context.Context, qs pika.QuerySet[T], req pika.Paginatable, options pika.AIPFilterOptions, conv Converter[T, M]) (*ListResult[M], error) {
+	var count int
+	rows, nextPageToken, err := qs.GetPage(ctx, req, options, &count)
+	if err != nil {
+		return nil, Status(err)
+	}
+
+	items := make([]M, 0, len(rows))
+	for _, row := range rows {
+		msg, err := conv.ToProto(row)
+		if err != nil {
+			return nil, Status(err)
+		}
+		items = append(items, msg)
+	}
+
+	return &ListResult[M]{
+		Items:         items,
+		NextPageToken: nextPageToken,
+		TotalSize:     int32(min(count, math.MaxInt32)),
+	}, nil
+}
+
+// GetHandler implements an AIP-131 Get method, returning the row with the given primary key.
+// Returns NotFound if there is no such row.
+func GetHandler[T any, M proto.Message](ctx context.Context, qs pika.QuerySet[T], conv Converter[T, M], keys ...any) (M, error) {
+	var zero M
+
+	x, err := qs.GetByPK(ctx, keys...)
+	if err != nil {
+		return zero, Status(err)
+	}
+
+	msg, err := conv.ToProto(x)
+	if err != nil {
+		return zero, Status(err)
+	}
+
+	return msg, nil
+}
+
+// CreateHandler implements an AIP-133 Create method.
+// The created row is returned, including columns set by the database such as the primary key.
+// Returns AlreadyExists if a unique constraint is violated.
+func CreateHandler[T any, M proto.Message](ctx context.Context, qs pika.QuerySet[T], conv Converter[T, M], msg M) (M, error) {
+	var zero M
+
+	x, err := conv.FromProto(msg)
+	if err != nil {
+		return zero, Status(err)
+	}
+
+	err = qs.Create(ctx, x)
+	if err != nil {
+		return zero, Status(err)
+	}
+
+	ret, err := conv.ToProto(x)
+	if err != nil {
+		return zero, Status(err)
+	}
+
+	return ret, nil
+}
+
+// UpdateHandler implements an AIP-134 Update method, updating the fields in the update mask.
+// The row is selected by the primary key of msg, see QuerySet.UpdateMask for the mask semantics.
+// Returns NotFound if there is no such row, and InvalidArgument for invalid masks.
+func UpdateHandler[T any, M proto.Message](ctx context.Context, qs pika.QuerySet[T], conv Converter[T, M], msg M, mask *fieldmaskpb.FieldMask, opts pika.ProtoReflectOptions) (M, error) {
+	var zero M
+
+	x, err := conv.FromProto(msg)
+	if err != nil {
+		return zero, Status(err)
+	}
+
+	err = qs.UpdateMask(ctx, x, mask, msg, opts)
+	if err != nil {
+		return zero, Status(err)
+	}
+
+	ret, err := conv.ToProto(x)
+	if err != nil {
+		return zero, Status(err)
+	}
+
+	return ret, nil
+}
+
+// DeleteHandler implements an AIP-135 Delete method, deleting the row with the given primary key.
+// The row is loaded first, so NotFound is returned if there is no such row,
+// and BeforeDelete hooks are called with the row.
+func DeleteHandler[T any](ctx context.Context, qs pika.QuerySet[T], keys ...any) error {
+	x, err := qs.GetByPK(ctx, keys...)
+	if err != nil {
+		return Status(err)
+	}
+
+	err = qs.D(ctx, x)
+	if err != nil {
+		return Status(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcsvc
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.ciq.dev/pika"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorCodes maps typed pika errors to gRPC codes
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{pika.ErrNotFound, codes.NotFound},
+	{pika.ErrUniqueViolation, codes.AlreadyExists},
+	{pika.ErrForeignKeyViolation, codes.FailedPrecondition},
+	{pika.ErrCheckViolation, codes.InvalidArgument},
+	{pika.ErrSerialization, codes.Aborted},
+	{pika.ErrInvalidFilter, codes.InvalidArgument},
+}
+
+// invalidArgumentErrors are returned for invalid requests, such as invalid filters or page tokens
+var invalidArgumentErrors = []error{
+	pika.ErrInvalidSuffix,
+	pika.ErrIdentifierNotAcceptable,
+	pika.ErrNestedExpressionsNotSupported,
+	pika.ErrCannotCombineMultipleValues,
+	pika.ErrTypeNotAccepted,
+	pika.ErrValueNotAccepted,
+	pika.ErrUnexpectedIdentifier,
+	pika.ErrMissingOperator,
+	pika.ErrMissingIdentifier,
+	pika.ErrIdentifierNotAllowed,
+	pika.ErrUnexpectedToken,
+	pika.ErrInvalidFieldMask,
+	pika.ErrPageTokenDecode,
+	pika.ErrPageSizeTooSmall,
+	pika.ErrPageSizeTooLarge,
+	pika.ErrPageSizeNegative,
+	pika.ErrOffsetTooLarge,
+	pika.ErrPageSizeValueTooLarge,
+	pika.ErrPrimaryKeyCount,
+	pika.ErrPrimaryKeyUpdate,
+}
+
+// pqErrorCodes maps PostgreSQL error codes without a typed pika error to gRPC codes
+var pqErrorCodes = map[pq.ErrorCode]codes.Code{
+	"23502": codes.InvalidArgument, // not_null_violation
+	"22001": codes.InvalidArgument, // string_data_right_truncation
+	"22P02": codes.InvalidArgument, // invalid_text_representation
+	"55P03": codes.Aborted,         // lock_not_available
+}
+
+// Status returns err as a gRPC status error.
+// pika.ErrNotFound and sql.ErrNoRows are NotFound, invalid filters, page tokens and field masks are InvalidArgument,
+// constraint violations are mapped by their kind, for example unique violations are AlreadyExists,
+// and other PostgreSQL errors are mapped by their code.
+// Invalid filters have a BadRequest detail for the filter field, with the annotated message of the pika.FilterSyntaxError.
+// PostgreSQL errors only send their message and the constraint or column, not the detail with the row values.
+// Errors that already have a status keep it, and other errors are Internal without their message,
+// so queries are not leaked to clients.
+func Status(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	s := status.New(Code(err), message(err))
+
+	// Invalid filters point at the offending token in the details
+	var syntaxErr *pika.FilterSyntaxError
+	if errors.As(err, &syntaxErr) {
+		withDetails, detailsErr := s.WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "filter", Description: syntaxErr.Annotated()},
+			},
+		})
+		if detailsErr == nil {
+			s = withDetails
+		}
+	}
+
+	return s.Err()
+}
+
+// Code returns the gRPC code for err, see Status
+func Code(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+
+	if s, ok := status.FromError(err); ok {
+		return s.Code()
+	}
+
+	for _, target := range errorCodes {
+		if errors.Is(err, target.err) {
+			return target.code
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return codes.NotFound
+	}
+	if errors.Is(err, context.Canceled) {
+		return codes.Canceled
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return codes.DeadlineExceeded
+	}
+
+	for _, target := range invalidArgumentErrors {
+		if errors.Is(err, target) {
+			return codes.InvalidArgument
+		}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if code, ok := pqErrorCodes[pqErr.Code]; ok {
+			return code
+		}
+	}
+
+	return codes.Internal
+}
+
+// message returns the message of the status for err
+func message(err error) string {
+	switch Code(err) {
+	case codes.Internal:
+		return "internal error"
+	case codes.NotFound:
+		return "not found"
+	}
+
+	// Constraint violations describe the constraint, not the query.
+	// The detail is not sent, as it contains the values of the row.
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Constraint != "" && !strings.Contains(pqErr.Message, pqErr.Constraint):
+			return fmt.Sprintf("%s (constraint %q)", pqErr.Message, pqErr.Constraint)
+		case pqErr.Column != "" && !strings.Contains(pqErr.Message, pqErr.Column):
+			return fmt.Sprintf("%s (column %q)", pqErr.Message, pqErr.Column)
+		}
+		return pqErr.Message
+	}
+
+	return err.Error()
+}
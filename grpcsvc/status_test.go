@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package grpcsvc
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.ciq.dev/pika"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCode(t *testing.T) {
+	require.Equal(t, codes.OK, Code(nil))
+	require.Equal(t, codes.NotFound, Code(fmt.Errorf("getting row: %w", sql.ErrNoRows)))
+	require.Equal(t, codes.Canceled, Code(context.Canceled))
+	require.Equal(t, codes.DeadlineExceeded, Code(context.DeadlineExceeded))
+	require.Equal(t, codes.InvalidArgument, Code(fmt.Errorf("%w: title", pika.ErrIdentifierNotAllowed)))
+	require.Equal(t, codes.InvalidArgument, Code(pika.ErrPageTokenDecode))
+	require.Equal(t, codes.InvalidArgument, Code(fmt.Errorf("%w: id", pika.ErrInvalidFieldMask)))
+	require.Equal(t, codes.NotFound, Code(fmt.Errorf("%w: article", pika.ErrNotFound)))
+	require.Equal(t, codes.InvalidArgument, Code(&pika.FilterSyntaxError{Err: pika.ErrUnexpectedToken}))
+	require.Equal(t, codes.AlreadyExists, Code(&pika.ConstraintError{Kind: pika.ErrUniqueViolation, Err: &pq.Error{Code: "23505"}}))
+	require.Equal(t, codes.FailedPrecondition, Code(&pika.ConstraintError{Kind: pika.ErrForeignKeyViolation, Err: &pq.Error{Code: "23503"}}))
+	require.Equal(t, codes.InvalidArgument, Code(&pika.ConstraintError{Kind: pika.ErrCheckViolation, Err: &pq.Error{Code: "23514"}}))
+	require.Equal(t, codes.Aborted, Code(fmt.Errorf("commit: %w: %w", pika.ErrSerialization, &pq.Error{Code: "40001"})))
+	require.Equal(t, codes.InvalidArgument, Code(&pq.Error{Code: "23502"}))
+	require.Equal(t, codes.Internal, Code(&pq.Error{Code: "42P01"}))
+	require.Equal(t, codes.Internal, Code(pika.ErrNotTracked))
+	require.Equal(t, codes.PermissionDenied, Code(status.Error(codes.PermissionDenied, "denied")))
+}
+
+func TestStatus(t *testing.T) {
+	require.Nil(t, Status(nil))
+
+	s, _ := status.FromError(Status(sql.ErrNoRows))
+	require.Equal(t, codes.NotFound, s.Code())
+	require.Equal(t, "not found", s.Message())
+
+	s, _ = status.FromError(Status(fmt.Errorf("%w: title", pika.ErrIdentifierNotAllowed)))
+	require.Equal(t, codes.InvalidArgument, s.Code())
+	require.Equal(t, "identifier is not allowed: title", s.Message())
+
+	// Internal errors don't leak queries
+	s, _ = status.FromError(Status(fmt.Errorf("query SELECT * FROM secrets: %w", &pq.Error{Code: "42P01"})))
+	require.Equal(t, codes.Internal, s.Code())
+	require.Equal(t, "internal error", s.Message())
+
+	s, _ = status.FromError(Status(&pika.ConstraintError{
+		Kind: pika.ErrUniqueViolation,
+		Err: &pq.Error{
+			Code:    "23505",
+			Message: `duplicate key value violates unique constraint "simple_model_1_title_key"`,
+			Detail:  "Key (title)=(Test) already exists.",
+		},
+	}))
+	require.Equal(t, codes.AlreadyExists, s.Code())
+	require.Equal(t, `duplicate key value violates unique constraint "simple_model_1_title_key"`, s.Message())
+
+	// The detail with the row values is not sent
+	s, _ = status.FromError(Status(&pika.ConstraintError{
+		Kind: pika.ErrCheckViolation,
+		Err: &pq.Error{
+			Code:       "23514",
+			Message:    `new row for relation "simple_model_1" violates check constraint "title_length"`,
+			Detail:     "Failing row contains (1, secret, Test).",
+			Constraint: "title_length",
+		},
+	}))
+	require.Equal(t, codes.InvalidArgument, s.Code())
+	require.Equal(t, `new row for relation "simple_model_1" violates check constraint "title_length"`, s.Message())
+
+	s, _ = status.FromError(Status(&pq.Error{
+		Code:    "23502",
+		Message: "null value in column violates not-null constraint",
+		Detail:  "Failing row contains (1, secret, null).",
+		Column:  "title",
+	}))
+	require.Equal(t, codes.InvalidArgument, s.Code())
+	require.Equal(t, `null value in column violates not-null constraint (column "title")`, s.Message())
+
+	// Invalid filters point at the token in the details
+	s, _ = status.FromError(Status(&pika.FilterSyntaxError{
+		Filter: "titel = 1",
+		Line:   1,
+		Column: 1,
+		Token:  "titel",
+		Err:    fmt.Errorf("%w: titel", pika.ErrIdentifierNotAllowed),
+	}))
+	require.Equal(t, codes.InvalidArgument, s.Code())
+	require.Equal(t, "identifier is not allowed: titel", s.Message())
+	require.Len(t, s.Details(), 1)
+	badRequest, ok := s.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Equal(t, "filter", badRequest.GetFieldViolations()[0].GetField())
+	require.Equal(t, "line 1, column 1: identifier is not allowed: titel\ntitel = 1\n^^^^^", badRequest.GetFieldViolations()[0].GetDescription())
+
+	// Statuses are kept
+	err := status.Error(codes.PermissionDenied, "denied")
+	require.Equal(t, err, Status(err))
+}
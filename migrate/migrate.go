@@ -0,0 +1,505 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+// Package migrate applies versioned SQL migrations to a pika.PostgreSQL database.
+//
+// Migrations are read from a fs.FS, usually an embed.FS, and named
+// <version>_<name>.up.sql and <version>_<name>.down.sql, for example:
+//
+//	0001_create_users.up.sql
+//	0001_create_users.down.sql
+//
+// The down migration is optional. Applied migrations are recorded in the
+// schema_migrations table, together with a checksum of the up migration.
+// Each migration runs in its own transaction, and an advisory lock makes
+// sure only one migrator runs at a time.
+//
+// Migrations are applied in version order. A pending migration older than
+// the last applied migration, for example one merged from another branch,
+// is refused with ErrOutOfOrder instead of being applied after newer ones.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"go.ciq.dev/pika"
+)
+
+// Static errors for err113 compliance
+var (
+	ErrInvalidFileName    = errors.New("invalid migration file name")
+	ErrDuplicateMigration = errors.New("duplicate migration")
+	ErrMissingUp          = errors.New("missing up migration")
+	ErrMissingDown        = errors.New("missing down migration")
+	ErrMissingMigration   = errors.New("applied migration is missing")
+	ErrChecksumMismatch   = errors.New("applied migration was modified")
+	ErrUnknownVersion     = errors.New("unknown migration version")
+	ErrOutOfOrder         = errors.New("pending migration is older than the last applied migration")
+)
+
+const (
+	// TableName is the table applied migrations are recorded in
+	TableName = "schema_migrations"
+
+	// lockKey is the key of the advisory lock held while migrating ("pika")
+	lockKey = 0x70696b61
+
+	upSuffix   = ".up.sql"
+	downSuffix = ".down.sql"
+)
+
+// Migration is a versioned schema change
+type Migration struct {
+	// Version orders the migrations, it is the number before the first underscore
+	Version int64
+	// Name is the part of the file name after the version
+	Name string
+	// Up is the SQL applying the migration
+	Up string
+	// Down is the SQL reverting the migration, empty if there is no down migration
+	Down string
+	// Checksum is the SHA-256 of Up
+	Checksum string
+}
+
+// Status is the state of a migration
+type Status struct {
+	Version int64
+	Name    string
+	// Applied is true if the migration is applied
+	Applied bool
+	// AppliedAt is when the migration was applied
+	AppliedAt time.Time
+	// Modified is true if the up migration changed after it was applied
+	Modified bool
+	// Missing is true if the migration is applied, but doesn't exist anymore
+	Missing bool
+	// OutOfOrder is true if the migration is pending, but older than the last applied migration
+	OutOfOrder bool
+}
+
+// Migrator applies migrations to a database
+type Migrator struct {
+	psql       *pika.PostgreSQL
+	migrations []*Migration
+}
+
+type appliedMigration struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	Checksum  string    `db:"checksum"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// New returns a Migrator for the migrations in the root of fsys.
+// Use fs.Sub if the migrations are in a subdirectory.
+func New(psql *pika.PostgreSQL, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		psql:       psql,
+		migrations: migrations,
+	}, nil
+}
+
+// Load reads the migrations in the root of fsys, ordered by version.
+// Files not ending in .up.sql or .down.sql are ignored.
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	hasUp := map[int64]bool{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || (!strings.HasSuffix(fileName, upSuffix) && !strings.HasSuffix(fileName, downSuffix)) {
+			continue
+		}
+
+		version, name, up, err := parseFileName(fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(fsys, fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{
+				Version: version,
+				Name:    name,
+			}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return nil, fmt.Errorf("%w: %d (%s and %s)", ErrDuplicateMigration, version, migration.Name, name)
+		}
+
+		if up {
+			if hasUp[version] {
+				return nil, fmt.Errorf("%w: %d", ErrDuplicateMigration, version)
+			}
+			hasUp[version] = true
+			migration.Up = string(content)
+			migration.Checksum = checksum(migration.Up)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if !hasUp[migration.Version] {
+			return nil, fmt.Errorf("%w: %d_%s", ErrMissingUp, migration.Version, migration.Name)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// parseFileName returns the version and name of a migration file,
+// and true if it is an up migration
+func parseFileName(fileName string) (int64, string, bool, error) {
+	up := strings.HasSuffix(fileName, upSuffix)
+	base := strings.TrimSuffix(strings.TrimSuffix(path.Base(fileName), upSuffix), downSuffix)
+
+	versionStr, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", false, fmt.Errorf("%w: %s", ErrInvalidFileName, fileName)
+	}
+
+	version, err := strconv.ParseInt(versionStr, 10, 64)
+	if err != nil || version < 1 {
+		return 0, "", false, fmt.Errorf("%w: %s", ErrInvalidFileName, fileName)
+	}
+
+	return version, name, up, nil
+}
+
+func checksum(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// Migrations returns the migrations, ordered by version
+func (m *Migrator) Migrations() []*Migration {
+	return m.migrations
+}
+
+// Up applies all pending migrations.
+// Returns ErrOutOfOrder without applying anything if a pending migration is older than the last applied migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.migrate(ctx, func(conn *sqlx.Conn, applied map[int64]*appliedMigration) error {
+		err := m.checkOrder(applied, math.MaxInt64)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if applied[migration.Version] != nil {
+				continue
+			}
+
+			err := m.apply(ctx, conn, migration)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down reverts the last applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.migrate(ctx, func(conn *sqlx.Conn, applied map[int64]*appliedMigration) error {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if applied[m.migrations[i].Version] != nil {
+				return m.revert(ctx, conn, m.migrations[i])
+			}
+		}
+
+		return nil
+	})
+}
+
+// To applies or reverts migrations until version is the last applied migration.
+// Version 0 reverts all migrations.
+// Returns ErrOutOfOrder without changing anything if a migration up to version is pending,
+// but older than the last applied migration up to version.
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	if version != 0 && m.migration(version) == nil {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.migrate(ctx, func(conn *sqlx.Conn, applied map[int64]*appliedMigration) error {
+		err := m.checkOrder(applied, version)
+		if err != nil {
+			return err
+		}
+
+		// Revert newer migrations first, newest first
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if migration.Version > version && applied[migration.Version] != nil {
+				err := m.revert(ctx, conn, migration)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= version && applied[migration.Version] == nil {
+				err := m.apply(ctx, conn, migration)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Status returns the state of all migrations, including applied migrations that don't exist anymore.
+// Ordered by version. Status only reads the migrations table, it doesn't take the migration lock
+// or create the table. Without the table, all migrations are pending.
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	var exists bool
+	err := m.psql.DB().GetContext(ctx, &exists, "SELECT to_regclass($1) IS NOT NULL", fmt.Sprintf("\"%s\"", TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[int64]*appliedMigration{}
+	if exists {
+		applied, err = appliedMigrations(ctx, m.psql.DB())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var latest int64
+	for version := range applied {
+		latest = max(latest, version)
+	}
+
+	statuses := make([]*Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := &Status{
+			Version: migration.Version,
+			Name:    migration.Name,
+		}
+		if a := applied[migration.Version]; a != nil {
+			status.Applied = true
+			status.AppliedAt = a.AppliedAt
+			status.Modified = a.Checksum != migration.Checksum
+		} else {
+			status.OutOfOrder = migration.Version < latest
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, a := range applied {
+		if m.migration(a.Version) == nil {
+			statuses = append(statuses, &Status{
+				Version:   a.Version,
+				Name:      a.Name,
+				Applied:   true,
+				AppliedAt: a.AppliedAt,
+				Missing:   true,
+			})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+func (m *Migrator) migration(version int64) *Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+
+	return nil
+}
+
+// migrate calls fn with a connection holding the migration lock and the verified applied migrations
+func (m *Migrator) migrate(ctx context.Context, fn func(conn *sqlx.Conn, applied map[int64]*appliedMigration) error) error {
+	return m.withLock(ctx, func(conn *sqlx.Conn) error {
+		applied, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		err = m.verify(applied)
+		if err != nil {
+			return err
+		}
+
+		return fn(conn, applied)
+	})
+}
+
+// withLock calls fn with a connection holding the migration lock.
+// The migrations table is created if it doesn't exist.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	// Advisory locks are held by the session, so use the same connection for all statements
+	conn, err := m.psql.DB().Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey)
+	if err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer func() {
+		// Use a new context, so the lock is released even if ctx is canceled
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+	}()
+
+	err = createTable(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// checkOrder returns ErrOutOfOrder if a migration up to version is pending,
+// but older than the last applied migration up to version
+func (m *Migrator) checkOrder(applied map[int64]*appliedMigration, version int64) error {
+	var latest int64
+	for v := range applied {
+		if v <= version {
+			latest = max(latest, v)
+		}
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version < latest && applied[migration.Version] == nil {
+			return fmt.Errorf("%w: %d_%s (last applied is %d)", ErrOutOfOrder, migration.Version, migration.Name, latest)
+		}
+	}
+
+	return nil
+}
+
+// verify returns an error if an applied migration is missing or was modified
+func (m *Migrator) verify(applied map[int64]*appliedMigration) error {
+	for _, a := range applied {
+		migration := m.migration(a.Version)
+		if migration == nil {
+			return fmt.Errorf("%w: %d_%s", ErrMissingMigration, a.Version, a.Name)
+		}
+		if migration.Checksum != a.Checksum {
+			return fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, a.Version, a.Name)
+		}
+	}
+
+	return nil
+}
+
+// apply runs the up migration and records it in one transaction
+func (m *Migrator) apply(ctx context.Context, conn *sqlx.Conn, migration *Migration) error {
+	return inTx(ctx, conn, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, migration.Up)
+		if err != nil {
+			return fmt.Errorf("applying migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO \"%s\" (version, name, checksum) VALUES ($1, $2, $3)", TableName), migration.Version, migration.Name, migration.Checksum)
+		return err
+	})
+}
+
+// revert runs the down migration and removes the record in one transaction
+func (m *Migrator) revert(ctx context.Context, conn *sqlx.Conn, migration *Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("%w: %d_%s", ErrMissingDown, migration.Version, migration.Name)
+	}
+
+	return inTx(ctx, conn, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, migration.Down)
+		if err != nil {
+			return fmt.Errorf("reverting migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM \"%s\" WHERE version = $1", TableName), migration.Version)
+		return err
+	})
+}
+
+func inTx(ctx context.Context, conn *sqlx.Conn, fn func(tx *sqlx.Tx) error) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = fn(tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func createTable(ctx context.Context, conn *sqlx.Conn) error {
+	_, err := conn.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS "%s" (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`, TableName))
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", TableName, err)
+	}
+
+	return nil
+}
+
+func appliedMigrations(ctx context.Context, q sqlx.QueryerContext) (map[int64]*appliedMigration, error) {
+	var rows []*appliedMigration
+	err := sqlx.SelectContext(ctx, q, &rows, fmt.Sprintf("SELECT version, name, checksum, applied_at FROM \"%s\" ORDER BY version", TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]*appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
@@ -7,6 +7,8 @@ import (
 	"context"
 
 	orderedmap "github.com/wk8/go-ordered-map/v2"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 var (
@@ -14,10 +16,15 @@ var (
 	PikaMetadataTableName = "PikaTableName"
 	// PikaMetadataDefaultOrderBy is the field name used to specify default ordering in struct tags.
 	PikaMetadataDefaultOrderBy = "PikaDefaultOrderBy"
+	// PikaMetadataTenantColumn is the field name used to specify the tenant column in struct tags.
+	// Queries on models with a tenant column are scoped to the tenant set with WithTenant or SetTenant.
+	// Only the model of the query set is scoped, joined tenant models are not.
+	PikaMetadataTenantColumn = "PikaTenantColumn"
 	// PikaMetadataFields contains all available metadata field names for Pika configuration.
 	PikaMetadataFields = []string{
 		PikaMetadataTableName,
 		PikaMetadataDefaultOrderBy,
+		PikaMetadataTenantColumn,
 	}
 )
 
@@ -103,23 +110,120 @@ type QuerySet[T any] interface {
 	// All filters will be applied
 	Delete(ctx context.Context) error
 
+	// Track remembers the column values of the values loaded by Get, GetOrNil, All,
+	// Iter, IterCursor and Chunked in tracker, so Save only updates the columns that changed.
+	// Example:
+	// 	tracker := NewTracker[T]()
+	// 	x, err := Q[T](psql).Track(tracker).Get(ctx)
+	// 	x.Name = ""
+	// 	err = Q[T](psql).Save(ctx, tracker, x)
+	Track(tracker *Tracker[T]) QuerySet[T]
+
+	// Save updates the columns of a tracked value that changed since it was loaded or saved.
+	// Empty values are also written, even if the column has "omitempty" in the pika tag.
+	// No query is made if nothing changed.
+	// The primary key is used as the filter, other filters applied to the query set are also inherited.
+	// Returns ErrNotTracked if the value is not tracked by tracker.
+	Save(ctx context.Context, tracker *Tracker[T], value *T) error
+
+	// UpdateFields updates the given columns of a value, including empty values.
+	// The primary key is used as the filter, other filters applied to the query set are also inherited.
+	// Example:
+	// 	UpdateFields(ctx, x, "name", "status")
+	UpdateFields(ctx context.Context, value *T, columns ...string) error
+
+	// UpdateMask updates the columns of the fields in an AIP-134 update mask, including empty values.
+	// Paths are proto field names of msg, mapped to columns the same way as in ProtoReflect.
+	// Nested paths are flattened, for example spec.image is the spec_image column,
+	// a message updates all of its flattened columns, and fields of JSONB messages update the whole column.
+	// "*" updates all fields, and an empty mask updates the fields set in msg.
+	// Returns ErrInvalidFieldMask if a path is not a field of msg, is excluded, has no column or is part of the primary key.
+	// The primary key is used as the filter, other filters applied to the query set are also inherited.
+	// Example:
+	// 	x, err := FromProto[Article](req.Article)
+	// 	err = Q[Article](psql).UpdateMask(ctx, x, req.UpdateMask, req.Article, ProtoReflectOptions{})
+	UpdateMask(ctx context.Context, value *T, mask *fieldmaskpb.FieldMask, msg proto.Message, opts ProtoReflectOptions) error
+
 	// GetOrNil returns a single value or nil
-	// Multiple values will return an error.
+	// Multiple values will return ErrMultipleRows,
+	// unless an OrderBy or a lock is set, then the first value is returned.
 	// Ignores Limit
 	GetOrNil(ctx context.Context) (*T, error)
 
 	// Get returns a single value
-	// Returns error if no value is found
-	// Returns error if multiple values are found
+	// Returns ErrNotFound if no value is found
+	// Returns ErrMultipleRows if multiple values are found,
+	// unless an OrderBy or a lock is set, then the first value is returned.
 	// Ignores Limit
 	Get(ctx context.Context) (*T, error)
 
+	// GetByPK returns the value with the given primary key
+	// The keys are given in the order of the primary key columns, see ModelInfo.PrimaryKey.
+	// All filters will be applied
+	// Example:
+	// 	GetByPK(ctx, orgID, userID)
+	GetByPK(ctx context.Context, keys ...any) (*T, error)
+
 	// All returns all values
 	All(ctx context.Context) ([]*T, error)
 
+	// Iter streams all values, scanning one row at a time instead of loading all of them.
+	// The returned function is compatible with iter.Seq2[*T, error] and can be ranged over in Go 1.23+.
+	// Iteration stops after the first error.
+	// Example:
+	// 	for x, err := range qs.Iter(ctx) {
+	// 		...
+	// 	}
+	Iter(ctx context.Context) func(yield func(*T, error) bool)
+
+	// IterCursor is like Iter, but uses a server-side cursor fetching fetchSize rows at a time.
+	// The cursor is declared in the active transaction, or in a new read-only transaction
+	// that is rolled back once iteration stops.
+	IterCursor(ctx context.Context, fetchSize int) func(yield func(*T, error) bool)
+
+	// Chunked calls fn with chunks of at most size values, paging by the primary key.
+	// The order of the query is replaced with the primary key order and offset is ignored.
+	// Composite primary keys are compared as a row value, so (a, b) > ($1, $2).
+	// Returning an error from fn stops the iteration and returns the error.
+	Chunked(ctx context.Context, size int, fn func([]*T) error) error
+
 	// Count returns the number of values
+	// If Distinct or DistinctOn is set, only distinct rows are counted
 	Count(ctx context.Context) (int, error)
 
+	// Exists returns true if at least one row matches the query
+	// Order by is ignored
+	Exists(ctx context.Context) (bool, error)
+
+	// Distinct removes duplicate rows from the result
+	Distinct() QuerySet[T]
+
+	// DistinctOn keeps only the first row of each set of rows where the given columns are equal
+	// The columns are prepended to the order of the query, as required by PostgreSQL.
+	// An existing order on the same columns keeps its direction.
+	// Example:
+	// 	DistinctOn("user_id").OrderBy("-created_at")
+	DistinctOn(columns ...string) QuerySet[T]
+
+	// ForUpdate locks the selected rows against concurrent updates (SELECT ... FOR UPDATE)
+	// Only the rows of T are locked if the query has joins.
+	// Requires a transaction, see PostgreSQL.Begin.
+	// Example:
+	// 	Filter("status=:s").OrderBy("created_at").Limit(10).ForUpdate().SkipLocked().All(ctx)
+	ForUpdate() QuerySet[T]
+
+	// ForShare locks the selected rows against concurrent updates, while allowing other ForShare locks
+	// Requires a transaction, see PostgreSQL.Begin.
+	ForShare() QuerySet[T]
+
+	// NoWait makes a locking query fail instead of waiting for rows locked by another transaction
+	// Requires ForUpdate or ForShare
+	NoWait() QuerySet[T]
+
+	// SkipLocked makes a locking query skip rows locked by another transaction
+	// Requires ForUpdate or ForShare
+	SkipLocked() QuerySet[T]
+
 	// Limit sets the limit for the query
 	Limit(limit int) QuerySet[T]
 
@@ -155,35 +259,55 @@ type QuerySet[T any] interface {
 	// AllQuery returns the query and args for All
 	AllQuery() (string, []interface{})
 
+	// ExistsQuery returns the query and args for Exists
+	ExistsQuery() (string, []interface{})
+
 	// Extensions
 	// AIP-160 filtering for gRPC/Proto
 	// See https://google.aip.dev/160
 	AIP160(filter string, options AIPFilterOptions) (QuerySet[T], error)
 
 	// Page token functionality for gRPC
+	// The primary key is appended to the order, so the order is stable across pages.
 	// The count is optional and returns the total number of rows for the query.
 	// It is implemented as a variadic function to not break existing code.
 	GetPage(ctx context.Context, paginatable Paginatable, options AIPFilterOptions, count ...*int) ([]*T, string, error)
 
 	// Join table
+	// Joined tenant models are not scoped to the tenant, filter them explicitly.
 	InnerJoin(modelFirst, modelSecond interface{}, keyFirst, keySecond string) QuerySet[T]
 	LeftJoin(modelFirst, modelSecond interface{}, keyFirst, keySecond string) QuerySet[T]
 	RightJoin(modelFirst, modelSecond interface{}, keyFirst, keySecond string) QuerySet[T]
 	FullJoin(modelFirst, modelSecond interface{}, keyFirst, keySecond string) QuerySet[T]
 
+	// InSchema sets the schema for all tables in the query, including joins and subqueries.
+	// Overrides the default schema of the connection, but not tables that are
+	// already qualified with a schema (for example PikaTableName "tenant.users").
+	InSchema(schema string) QuerySet[T]
+
 	// Exclude fields
 	Exclude(excludes ...string) QuerySet[T]
 	// Include fields
 	Include(includes ...string) QuerySet[T]
+	// WithReadMask includes the columns of the fields in an AIP-157 read mask.
+	// Paths are proto field names of msg, mapped to columns the same way as in ProtoReflect.
+	// Nested paths are flattened, for example spec.image is the spec_image column, and spec selects all spec_ columns.
+	// Fields of messages stored in a JSONB column select the whole column.
+	// The primary key and the order by columns, including those set after the mask, are always included, so pagination keeps working.
+	// Fields without a column are ignored, an empty mask or "*" selects all columns.
+	// Unknown paths fail the query with ErrInvalidFieldMask.
+	// Example:
+	// 	Q[Article](psql).WithReadMask(req.ReadMask, &pb.Article{}, ProtoReflectOptions{}).GetPage(ctx, req, options)
+	WithReadMask(mask *fieldmaskpb.FieldMask, msg proto.Message, opts ProtoReflectOptions) QuerySet[T]
 
 	// EXPERIMENTAL
 	// The following methods are EXPERIMENTAL. Think of it as a sneak peek on what's coming.
 	// It is mostly to experiment with a simpler API for filtering, updating and querying.
 	// Feel free to test it out and provide feedback.
 
-	// U is a shorthand for Update. ID field is used as the filter.
+	// U is a shorthand for Update. The primary key is used as the filter.
 	// Other filters applied to the query set are also inherited.
-	// Returns an error if the ID field is not set or does not exist.
+	// Returns an error if the model has no primary key.
 	// Thus preventing accidental updates to all rows.
 	U(ctx context.Context, value *T) error
 
@@ -192,9 +316,9 @@ type QuerySet[T any] interface {
 	// Format is as follows: <KEY>, <VALUE> etc.
 	F(keyval ...any) QuerySet[T]
 
-	// D is a shorthand for Delete. ID field is used as the filter.
+	// D is a shorthand for Delete. The primary key is used as the filter.
 	// Other filters applied to the query set are also inherited.
-	// Returns an error if the ID field is not set or does not exist.
+	// Returns an error if the model has no primary key.
 	// Thus preventing accidental deletes to all rows.
 	D(ctx context.Context, value *T) error
 
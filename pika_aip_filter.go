@@ -5,8 +5,10 @@ package pika
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/antlr/antlr4/runtime/Go/antlr/v4"
 
@@ -31,6 +33,8 @@ var (
 	ErrMissingOperator               = errors.New("missing operator")
 	ErrMissingIdentifier             = errors.New("missing identifier")
 	ErrIdentifierNotAllowed          = errors.New("identifier is not allowed")
+	ErrUnexpectedToken               = errors.New("unexpected token")
+	ErrUnexpectedEndOfFilter         = errors.New("unexpected end of filter")
 )
 
 // The goal of this AIP Filter extension is to be able to parse
@@ -73,11 +77,26 @@ type AIPFilterIdentifier struct {
 
 	// Column name is the name of the column in the database.
 	// If empty, the identifier is used as the column name.
+	// JSON paths of JSONB columns are in the form of column->key, and are compared as text,
+	// unless a cast is appended, for example column->key::numeric.
 	ColumnName string
 
 	// IsRepeated is true if the identifier is a repeated field.
 	// This is used to determine how to apply the filter.
 	IsRepeated bool
+
+	// IsMap is true if the identifier is a map in a JSONB column.
+	// Maps are filtered by key, for example labels.env = "prod".
+	IsMap bool
+
+	// EnumValues are the names of the values of an enum identifier.
+	// They are listed in errors for values that are not accepted.
+	EnumValues []string
+
+	// ConvertValue converts a value before it is used as an argument,
+	// for example to format a duration as an interval.
+	// Returning an error rejects the value. It is not called for null.
+	ConvertValue func(value any) (any, error)
 }
 
 // AIPFilterOptions provides configuration for AIP-160 filter parsing,
@@ -87,7 +106,59 @@ type AIPFilterOptions struct {
 	Identifiers map[string]AIPFilterIdentifier
 
 	// AcceptableIdentifiers is a list of identifiers that are allowed
+	// If empty, all identifiers are allowed in filters.
 	AcceptableIdentifiers []string
+
+	// SortableIdentifiers is a list of identifiers that are allowed in order by.
+	// If nil, AcceptableIdentifiers is used.
+	SortableIdentifiers []string
+
+	// restricted is set by ProtoReflect, so only AcceptableIdentifiers are allowed
+	// even if no field of the message is filterable.
+	restricted bool
+}
+
+// identifierAllowed returns true if identifier can be used in filters
+func (a AIPFilterOptions) identifierAllowed(identifier string) bool {
+	if len(a.AcceptableIdentifiers) == 0 {
+		return !a.restricted
+	}
+
+	return contains(a.AcceptableIdentifiers, identifier)
+}
+
+// identifier returns the configuration of an identifier, and the identifier that is
+// checked against AcceptableIdentifiers.
+// Keys of map identifiers are resolved to the JSON path of the key, for example
+// labels.env is the env key of the labels identifier.
+func (a AIPFilterOptions) identifier(name string) (string, AIPFilterIdentifier, bool) {
+	if ident, ok := a.Identifiers[name]; ok {
+		return name, ident, true
+	}
+
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return name, AIPFilterIdentifier{}, false
+	}
+
+	mapName, key := name[:i], name[i+1:]
+	ident, ok := a.Identifiers[mapName]
+	if !ok || !ident.IsMap {
+		return name, AIPFilterIdentifier{}, false
+	}
+
+	// The cast of the values applies to the key
+	column, cast, _ := strings.Cut(ident.ColumnName, "::")
+	if column == "" {
+		column = mapName
+	}
+	ident.ColumnName = column + "->" + key
+	if cast != "" {
+		ident.ColumnName += "::" + cast
+	}
+	ident.IsMap = false
+
+	return mapName, ident, true
 }
 
 func (a AIPFilterOptions) verifyOrderBy(orderBy string) ([]string, error) {
@@ -123,7 +194,11 @@ func (a AIPFilterOptions) verifyOrderBy(orderBy string) ([]string, error) {
 		}
 
 		// Verify that the identifier is acceptable
-		if !contains(a.AcceptableIdentifiers, identifier) {
+		sortable := a.SortableIdentifiers
+		if sortable == nil {
+			sortable = a.AcceptableIdentifiers
+		}
+		if !contains(sortable, identifier) {
 			return nil, fmt.Errorf("%w: %s", ErrIdentifierNotAcceptable, identifier)
 		}
 
@@ -145,21 +220,129 @@ func NewAIPFilter[T any]() *AIPFilter[T] {
 	return &AIPFilter[T]{}
 }
 
-func (a *AIPFilter[T]) parseFilter(filter string) (*parser.FilterLexer, error) {
+// suggestIdentifiers returns the acceptable identifiers closest to an identifier that is not allowed
+func (a AIPFilterOptions) suggestIdentifiers(identifier string) []string {
+	type suggestion struct {
+		identifier string
+		distance   int
+	}
+
+	maxDistance := max(2, utf8.RuneCountInString(identifier)/3)
+
+	var suggestions []suggestion
+	for _, acceptable := range a.AcceptableIdentifiers {
+		distance := levenshtein(strings.ToLower(identifier), strings.ToLower(acceptable))
+		if distance <= maxDistance {
+			suggestions = append(suggestions, suggestion{acceptable, distance})
+		}
+	}
+
+	// Closest first, ties keep the order of AcceptableIdentifiers
+	slices.SortStableFunc(suggestions, func(x, y suggestion) int {
+		return x.distance - y.distance
+	})
+
+	var ret []string
+	for _, s := range suggestions[:min(len(suggestions), 3)] {
+		ret = append(ret, s.identifier)
+	}
+
+	return ret
+}
+
+// levenshtein returns the number of single character edits between a and b
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// filterErrorListener collects the first error of the lexer, for example an unterminated string.
+// Without it ANTLR prints the error to the console and skips the characters.
+type filterErrorListener struct {
+	*antlr.DefaultErrorListener
+	err *FilterSyntaxError
+}
+
+func (l *filterErrorListener) SyntaxError(recognizer antlr.Recognizer, _ any, line, column int, msg string, _ antlr.RecognitionException) {
+	if l.err != nil {
+		return
+	}
+
+	l.err = &FilterSyntaxError{
+		Line:   line,
+		Column: column + 1,
+		Err:    fmt.Errorf("%w: %s", ErrUnexpectedToken, msg),
+	}
+
+	// Report the characters the lexer couldn't match
+	if lexer, ok := recognizer.(*antlr.BaseLexer); ok {
+		input := lexer.GetInputStream()
+		text := input.GetTextFromInterval(antlr.NewInterval(lexer.TokenStartCharIndex, input.Index()))
+
+		l.err.Position = lexer.TokenStartCharIndex
+		l.err.Token = text
+		l.err.Err = fmt.Errorf("%w: %s", ErrUnexpectedToken, text)
+	}
+}
+
+// expectedTokens returns the token types accepted after prev, the last token that isn't whitespace
+func expectedTokens(state *pikaAip160State, prev antlr.Token, options AIPFilterOptions) []int {
+	if state.activeDot || state.activeIdentifier == "" {
+		return []int{parser.FilterLexerIDENTIFIER}
+	}
+
+	if prev != nil {
+		if _, ok := antlrOperators[prev.GetTokenType()]; ok {
+			_, cnf, _ := options.identifier(state.activeIdentifier)
+			if len(cnf.AcceptedTypes) > 0 {
+				return cnf.AcceptedTypes
+			}
+			return antlrValueTypes
+		}
+	}
+
+	return antlrComparators
+}
+
+func (a *AIPFilter[T]) parseFilter(filter string) (*parser.FilterLexer, *filterErrorListener, error) {
 	// If the filter is empty, return the QuerySet as is.
 	if filter == "" {
-		return nil, errors.New("filter string is empty")
+		return nil, nil, errors.New("filter string is empty")
 	}
 
 	input := antlr.NewInputStream(filter)
 	lexer := parser.NewFilterLexer(input)
 
-	return lexer, nil
+	// Collect lexer errors instead of printing them
+	listener := &filterErrorListener{DefaultErrorListener: antlr.NewDefaultErrorListener()}
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(listener)
+
+	return lexer, listener, nil
 }
 
 // AIPFilter parses the filter string from a gRPC request and
 // returns a QuerySet that can be used to query the database.
-func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOptions) (QuerySet[T], error) {
+func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOptions) (_ QuerySet[T], err error) {
 	// If empty, return the QuerySet as is.
 	if filter == "" {
 		return b, nil
@@ -188,7 +371,7 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 		}
 	}
 
-	lexer, err := a.parseFilter(filter)
+	lexer, listener, err := a.parseFilter(filter)
 	if err != nil {
 		return nil, err
 	}
@@ -203,12 +386,43 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 		},
 	}
 
+	// Errors from here on are reported at the current token
+	var t, prev antlr.Token
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		var syntaxErr *FilterSyntaxError
+		if !errors.As(err, &syntaxErr) {
+			if t == nil {
+				return
+			}
+			syntaxErr = newFilterSyntaxError(lexer, t, err)
+			err = syntaxErr
+		}
+		syntaxErr.Filter = filter
+	}()
+
 	i := 0
 	for {
 		activeState := states[i]
 
-		t := lexer.NextToken()
+		if t != nil && t.GetChannel() == antlr.TokenDefaultChannel {
+			prev = t
+		}
+
+		t = lexer.NextToken()
+		if listener.err != nil {
+			return nil, listener.err
+		}
+
 		if t.GetTokenType() == antlr.TokenEOF {
+			// The last expression is incomplete
+			if activeState.activeIdentifier != "" || activeState.activeOperator != "" {
+				return nil, newFilterSyntaxError(lexer, t, ErrUnexpectedEndOfFilter, expectedTokens(activeState, prev, options)...)
+			}
+
 			// If we had an activeParens (usually no parens, is an active parens too)
 			// We add the state to the QuerySet
 			// If it's empty, it's skipped anyways
@@ -291,6 +505,23 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 			parser.FilterLexerMINUS:
 			activeState.activeNot = true
 
+			continue
+
+		// If DOT, the identifier continues with a nested field, for example spec.image
+		case parser.FilterLexerDOT:
+			if activeState.activeIdentifier == "" || activeState.activeValue != nil || activeState.activeDot {
+				return nil, newFilterSyntaxError(lexer, t, fmt.Errorf("%w: %s", ErrUnexpectedToken, t.GetText()), expectedTokens(activeState, prev, options)...)
+			}
+			activeState.activeDot = true
+
+			continue
+		}
+
+		// Field after a DOT
+		if activeState.activeDot {
+			activeState.activeIdentifier += "." + t.GetText()
+			activeState.activeDot = false
+
 			continue
 		}
 
@@ -298,7 +529,7 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 		if activeState.activeNot {
 			// Manually handle has operator for array fields
 			if tokenType == parser.FilterLexerCOLON {
-				if x, ok := options.Identifiers[activeState.activeIdentifier]; ok {
+				if _, x, ok := options.identifier(activeState.activeIdentifier); ok {
 					if x.IsRepeated {
 						activeState.activeOperator = HintNotIn
 						continue
@@ -317,7 +548,7 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 		} else {
 			// Manually handle has operator for array fields
 			if tokenType == parser.FilterLexerCOLON {
-				if x, ok := options.Identifiers[activeState.activeIdentifier]; ok {
+				if _, x, ok := options.identifier(activeState.activeIdentifier); ok {
 					if x.IsRepeated {
 						activeState.activeOperator = HintIn
 						continue
@@ -390,6 +621,16 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 		case parser.FilterLexerFALSE:
 			activeState.activeValue = false
 			activeState.activeValueType = parser.FilterLexerFALSE
+		case parser.FilterLexerIDENTIFIER:
+			// Identifiers with value aliases or string values accept values without quotes,
+			// for example status = failed or labels.env = prod
+			_, cnf, _ := options.identifier(activeState.activeIdentifier)
+			bareValue := len(cnf.ValueAliases) > 0 || contains(cnf.AcceptedTypes, parser.FilterLexerSTRING)
+			if activeState.activeIdentifier != "" && activeState.activeValue == nil && bareValue {
+				activeState.activeValue = t.GetText()
+				activeState.activeValueType = parser.FilterLexerSTRING
+				tokenType = parser.FilterLexerSTRING
+			}
 		case parser.FilterLexerNULL:
 			// Null is an operator and a value
 			setOp := HintIsNull
@@ -406,7 +647,7 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 
 		// Check if value was set in previous switch
 		if lacksValue && activeState.activeValue != nil {
-			cnf, ok := options.Identifiers[activeState.activeIdentifier]
+			_, cnf, ok := options.identifier(activeState.activeIdentifier)
 			if ok {
 				// Check if we have a value alias
 				val := activeState.activeValue
@@ -461,7 +702,8 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 						}
 					}
 					if !isOk {
-						return nil, fmt.Errorf("%w: %s for identifier %s", ErrTypeNotAccepted, lexer.SymbolicNames[activeState.activeValueType], activeState.activeIdentifier)
+						err := fmt.Errorf("%w: %s for identifier %s%s", ErrTypeNotAccepted, lexer.SymbolicNames[activeState.activeValueType], activeState.activeIdentifier, cnf.validValues())
+						return nil, newFilterSyntaxError(lexer, t, err, cnf.AcceptedTypes...)
 					}
 				}
 
@@ -476,8 +718,17 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 						}
 					}
 					if !isOk {
-						return nil, fmt.Errorf("%w: %v for identifier %s", ErrValueNotAccepted, activeState.activeValue, activeState.activeIdentifier)
+						return nil, fmt.Errorf("%w: %v for identifier %s%s", ErrValueNotAccepted, activeState.activeValue, activeState.activeIdentifier, cnf.validValues())
+					}
+				}
+
+				// Convert the value for the column
+				if cnf.ConvertValue != nil && activeState.activeValueType != parser.FilterLexerNULL {
+					val, err := cnf.ConvertValue(activeState.activeValue)
+					if err != nil {
+						return nil, fmt.Errorf("%w: %v for identifier %s: %w", ErrValueNotAccepted, activeState.activeValue, activeState.activeIdentifier, err)
 					}
+					activeState.activeValue = val
 				}
 			}
 		}
@@ -487,9 +738,10 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 			// If we already have an identifier, then this is a value
 			if activeState.activeIdentifier == "" {
 				activeState.activeIdentifier = t.GetText()
+				activeState.identifierToken = t
 				continue
 			}
-			return nil, fmt.Errorf("%w: %s", ErrUnexpectedIdentifier, t.GetText())
+			return nil, newFilterSyntaxError(lexer, t, fmt.Errorf("%w: %s", ErrUnexpectedIdentifier, t.GetText()), expectedTokens(activeState, prev, options)...)
 		}
 
 		if activeState.activeOperator != "" && activeState.activeIdentifier != "" && activeState.activeValue != nil {
@@ -502,10 +754,16 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 			}
 
 			// Check if AcceptableIdentifiers are set, if so check if identifier is valid
-			if len(options.AcceptableIdentifiers) > 0 {
-				if !contains(options.AcceptableIdentifiers, activeState.activeIdentifier) {
-					return nil, fmt.Errorf("%w: %s", ErrIdentifierNotAllowed, activeState.activeIdentifier)
+			// Maps can only be filtered by key
+			acceptableIdentifier, cnf, ok := options.identifier(activeState.activeIdentifier)
+			if cnf.IsMap || !options.identifierAllowed(acceptableIdentifier) {
+				// Report the error at the identifier instead of the value
+				syntaxErr := newFilterSyntaxError(lexer, activeState.identifierToken, fmt.Errorf("%w: %s", ErrIdentifierNotAllowed, activeState.activeIdentifier))
+				syntaxErr.Token = activeState.activeIdentifier
+				if !cnf.IsMap {
+					syntaxErr.Suggestions = options.suggestIdentifiers(activeState.activeIdentifier)
 				}
+				return nil, syntaxErr
 			}
 
 			// If operator is __eq, then make it empty
@@ -523,7 +781,6 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 
 			dbColumn := activeState.activeIdentifier
 			// Check if we have a column name override
-			cnf, ok := options.Identifiers[activeState.activeIdentifier]
 			if ok {
 				if cnf.ColumnName != "" {
 					// If we have a column name override, use that instead
@@ -564,6 +821,7 @@ func (a *AIPFilter[T]) aip160(b QuerySet[T], filter string, options AIPFilterOpt
 			}
 
 			activeState.activeIdentifier = ""
+			activeState.identifierToken = nil
 			activeState.activeOperator = ""
 			activeState.activeValue = nil
 			if !activeState.forceNot {
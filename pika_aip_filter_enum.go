@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.ciq.dev/pika/parser"
+)
+
+// Static errors for err113 compliance
+var (
+	ErrNotEnum = errors.New("type is not an enum")
+)
+
+// AIPFilterEnum returns the configuration of an identifier for an enum with the given values.
+// Values are accepted by name, case insensitive, and by the last part of the name,
+// so STATUS_FAILED, status_failed and failed are the same value.
+// Values can also be used without quotes, for example status = failed.
+// String enums, such as Postgres ENUM types, are filtered by their value.
+// Integer enums are filtered by their number and named with their String method.
+// Example:
+//
+//	options := AIPFilterOptions{
+//		Identifiers: map[string]AIPFilterIdentifier{
+//			"status": AIPFilterEnum(StatusFailed, StatusSucceeded),
+//		},
+//	}
+func AIPFilterEnum[E ~string | ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32](values ...E) AIPFilterIdentifier {
+	ident := AIPFilterIdentifier{
+		ValueAliases:   map[any]any{},
+		AcceptedTypes:  []int{parser.FilterLexerNUM_INT},
+		AcceptedValues: []any{},
+	}
+	if reflect.TypeOf((*E)(nil)).Elem().Kind() == reflect.String {
+		ident.AcceptedTypes = []int{parser.FilterLexerSTRING}
+	}
+
+	for _, value := range values {
+		v := reflect.ValueOf(value)
+		if v.Kind() == reflect.String {
+			ident.addEnumValue(v.String(), v.String())
+			continue
+		}
+
+		num := convertTo[int64](v)
+		name := fmt.Sprint(num)
+		if stringer, ok := any(value).(fmt.Stringer); ok {
+			name = stringer.String()
+		}
+		ident.addEnumValue(name, num)
+	}
+
+	return ident
+}
+
+// AIPFilterEnumType returns the configuration of an identifier for a Postgres ENUM type.
+// The values are read from the database, see AIPFilterEnum.
+// The type name can be qualified with a schema.
+// Returns ErrNotEnum if the type has no values.
+func (p *PostgreSQL) AIPFilterEnumType(ctx context.Context, typeName string) (AIPFilterIdentifier, error) {
+	var values []string
+	err := p.DB().SelectContext(ctx, &values, `SELECT enumlabel FROM pg_enum
+		WHERE enumtypid = $1::regtype ORDER BY enumsortorder`, typeName)
+	if err != nil {
+		return AIPFilterIdentifier{}, err
+	}
+	if len(values) == 0 {
+		return AIPFilterIdentifier{}, fmt.Errorf("%w: %s", ErrNotEnum, typeName)
+	}
+
+	return AIPFilterEnum(values...), nil
+}
+
+// addEnumValue accepts an enum value, with aliases for its name and the last part of its name
+func (a *AIPFilterIdentifier) addEnumValue(name string, value any) {
+	a.ValueAliases[strings.ToLower(name)] = value
+
+	// The full name of another value takes precedence
+	parts := strings.Split(name, "_")
+	if lastValue := strings.ToLower(parts[len(parts)-1]); lastValue != strings.ToLower(name) {
+		if _, ok := a.ValueAliases[lastValue]; !ok {
+			a.ValueAliases[lastValue] = value
+		}
+	}
+
+	a.AcceptedValues = append(a.AcceptedValues, value)
+	a.EnumValues = append(a.EnumValues, name)
+}
+
+// validValues describes the values of an enum identifier for errors
+func (a *AIPFilterIdentifier) validValues() string {
+	if len(a.EnumValues) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (valid values: %s)", strings.Join(a.EnumValues, ", "))
+}
@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.ciq.dev/pika/parser"
+	pikatestpb "go.ciq.dev/pika/testproto"
+)
+
+type testPriority int32
+
+const (
+	testPriorityLow testPriority = iota + 1
+	testPriorityHigh
+)
+
+func (p testPriority) String() string {
+	switch p {
+	case testPriorityLow:
+		return "PRIORITY_LOW"
+	case testPriorityHigh:
+		return "PRIORITY_HIGH"
+	}
+
+	return "PRIORITY_UNKNOWN"
+}
+
+type testState string
+
+const (
+	testStateQueued testState = "queued"
+	testStateFailed testState = "STATE_FAILED"
+)
+
+func enumFilterOptions() AIPFilterOptions {
+	priority := AIPFilterEnum(testPriorityLow, testPriorityHigh)
+	priority.ColumnName = "id"
+	state := AIPFilterEnum(testStateQueued, testStateFailed)
+	state.ColumnName = "title"
+
+	return AIPFilterOptions{
+		Identifiers: map[string]AIPFilterIdentifier{
+			"priority": priority,
+			"state":    state,
+		},
+	}
+}
+
+func TestAIPFilterEnum(t *testing.T) {
+	priority := AIPFilterEnum(testPriorityLow, testPriorityHigh)
+	require.Equal(t, []int{parser.FilterLexerNUM_INT}, priority.AcceptedTypes)
+	require.Equal(t, []any{int64(1), int64(2)}, priority.AcceptedValues)
+	require.Equal(t, []string{"PRIORITY_LOW", "PRIORITY_HIGH"}, priority.EnumValues)
+	require.Equal(t, map[any]any{
+		"priority_low":  int64(1),
+		"low":           int64(1),
+		"priority_high": int64(2),
+		"high":          int64(2),
+	}, priority.ValueAliases)
+
+	state := AIPFilterEnum(testStateQueued, testStateFailed)
+	require.Equal(t, []int{parser.FilterLexerSTRING}, state.AcceptedTypes)
+	require.Equal(t, []any{"queued", "STATE_FAILED"}, state.AcceptedValues)
+	require.Equal(t, map[any]any{
+		"queued":       "queued",
+		"state_failed": "STATE_FAILED",
+		"failed":       "STATE_FAILED",
+	}, state.ValueAliases)
+}
+
+func TestAIPFilterEnumFilter(t *testing.T) {
+	psql := newPsql(t)
+	opts := enumFilterOptions()
+
+	tests := []struct {
+		filter string
+		args   []any
+	}{
+		{`state = failed`, []any{"STATE_FAILED"}},
+		{`state = STATE_FAILED`, []any{"STATE_FAILED"}},
+		{`state = "Queued"`, []any{"queued"}},
+		{`priority = high`, []any{int64(2)}},
+		{`priority = "PRIORITY_LOW"`, []any{int64(1)}},
+		{`priority = 2`, []any{int64(2)}},
+	}
+	for _, test := range tests {
+		qs, err := Q[simpleModel1](psql).AIP160(test.filter, opts)
+		require.Nil(t, err, test.filter)
+
+		_, args := qs.AllQuery()
+		require.Equal(t, test.args, args, test.filter)
+	}
+
+	qs, err := Q[simpleModel1](psql).AIP160(`state = failed AND priority != low`, opts)
+	require.Nil(t, err)
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."title" = $1 AND "simpleModel1"."id" != $2)`
+	actualQuery, actualArgs := qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+	require.Equal(t, []any{"STATE_FAILED", int64(1)}, actualArgs)
+
+	_, err = Q[simpleModel1](psql).AIP160(`state = running`, opts)
+	require.ErrorIs(t, err, ErrValueNotAccepted)
+	require.Equal(t, "value is not accepted for identifier: running for identifier state (valid values: queued, STATE_FAILED)", err.Error())
+
+	_, err = Q[simpleModel1](psql).AIP160(`priority = medium`, opts)
+	require.ErrorIs(t, err, ErrTypeNotAccepted)
+	require.Equal(t, "type is not accepted for identifier: STRING for identifier priority (valid values: PRIORITY_LOW, PRIORITY_HIGH)", err.Error())
+}
+
+func TestAIPFilterEnumProtoWithoutQuotes(t *testing.T) {
+	psql := newPsql(t)
+	opts := ProtoReflect(&pikatestpb.Complete3{})
+
+	qs, err := Q[protoModel4](psql).AIP160(`status = canceled OR status = STATUS_OK`, opts)
+	require.Nil(t, err)
+	_, args := qs.AllQuery()
+	require.Equal(t, []any{int64(3), int64(1)}, args)
+
+	// Only identifiers with value aliases or string values accept values without quotes
+	_, err = Q[protoModel4](psql).AIP160(`bool = hello`, opts)
+	require.ErrorIs(t, err, ErrUnexpectedIdentifier)
+}
+
+func TestAIPFilterEnumType(t *testing.T) {
+	psql := newPsql(t)
+	psql.db.MustExec("DROP TYPE IF EXISTS test_job_state")
+	psql.db.MustExec("CREATE TYPE test_job_state AS ENUM ('queued', 'running', 'failed')")
+	ctx := context.Background()
+
+	ident, err := psql.AIPFilterEnumType(ctx, "test_job_state")
+	require.Nil(t, err)
+	require.Equal(t, []string{"queued", "running", "failed"}, ident.EnumValues)
+	require.Equal(t, []any{"queued", "running", "failed"}, ident.AcceptedValues)
+
+	_, err = psql.AIPFilterEnumType(ctx, "text")
+	require.ErrorIs(t, err, ErrNotEnum)
+}
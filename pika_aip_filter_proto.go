@@ -4,25 +4,37 @@
 package pika
 
 import (
-	"strings"
+	"fmt"
+	"time"
 
 	"github.com/iancoleman/strcase"
 	"go.ciq.dev/pika/parser"
+	"go.ciq.dev/pika/pikapb"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 var (
 	protoKindToLexer = map[protoreflect.Kind]int{
-		protoreflect.StringKind: parser.FilterLexerSTRING,
-		protoreflect.FloatKind:  parser.FilterLexerNUM_FLOAT,
-		protoreflect.Int32Kind:  parser.FilterLexerNUM_INT,
-		protoreflect.Int64Kind:  parser.FilterLexerNUM_INT,
-		protoreflect.Uint32Kind: parser.FilterLexerNUM_UINT,
-		protoreflect.Uint64Kind: parser.FilterLexerNUM_UINT,
+		protoreflect.StringKind:   parser.FilterLexerSTRING,
+		protoreflect.BytesKind:    parser.FilterLexerSTRING,
+		protoreflect.FloatKind:    parser.FilterLexerNUM_FLOAT,
+		protoreflect.DoubleKind:   parser.FilterLexerNUM_FLOAT,
+		protoreflect.Int32Kind:    parser.FilterLexerNUM_INT,
+		protoreflect.Int64Kind:    parser.FilterLexerNUM_INT,
+		protoreflect.Sint32Kind:   parser.FilterLexerNUM_INT,
+		protoreflect.Sint64Kind:   parser.FilterLexerNUM_INT,
+		protoreflect.Sfixed32Kind: parser.FilterLexerNUM_INT,
+		protoreflect.Sfixed64Kind: parser.FilterLexerNUM_INT,
+		protoreflect.Uint32Kind:   parser.FilterLexerNUM_UINT,
+		protoreflect.Uint64Kind:   parser.FilterLexerNUM_UINT,
+		protoreflect.Fixed32Kind:  parser.FilterLexerNUM_UINT,
+		protoreflect.Fixed64Kind:  parser.FilterLexerNUM_UINT,
 	}
 	protoMessageKindToLexer = map[string]int{
 		"google.protobuf.StringValue": parser.FilterLexerSTRING,
+		"google.protobuf.BytesValue":  parser.FilterLexerSTRING,
 		"google.protobuf.Duration":    parser.FilterLexerDURATION,
 		"google.protobuf.Timestamp":   parser.FilterLexerTIMESTAMP,
 		"google.protobuf.DoubleValue": parser.FilterLexerNUM_FLOAT,
@@ -31,42 +43,143 @@ var (
 		"google.protobuf.Int64Value":  parser.FilterLexerNUM_INT,
 		"google.protobuf.UInt32Value": parser.FilterLexerNUM_UINT,
 		"google.protobuf.UInt64Value": parser.FilterLexerNUM_UINT,
+		protoDate:                     parser.FilterLexerSTRING,
+		protoMoney:                    parser.FilterLexerNUM_FLOAT,
 	}
+	// protoJSONCasts are the casts of JSON values by accepted type, as JSON paths are extracted as text.
+	// Strings and bytes are compared as text.
+	protoJSONCasts = map[int]string{
+		parser.FilterLexerNUM_INT:   "numeric",
+		parser.FilterLexerNUM_UINT:  "numeric",
+		parser.FilterLexerNUM_FLOAT: "numeric",
+		parser.FilterLexerTRUE:      "boolean",
+		parser.FilterLexerTIMESTAMP: "timestamptz",
+		parser.FilterLexerDURATION:  "interval",
+	}
+	// protoUnsupportedMessages have no column representation, so fields of these types are skipped
+	protoUnsupportedMessages = []string{
+		"google.protobuf.Any",
+		"google.protobuf.FieldMask",
+		"google.protobuf.Struct",
+		"google.protobuf.Value",
+		"google.protobuf.ListValue",
+		"google.protobuf.Empty",
+	}
+)
+
+const (
+	// protoDate is filtered with a quoted date, for example birth_date > "2000-01-31",
+	// against a DATE column
+	protoDate = "google.type.Date"
+	// protoMoney is filtered with a number against a NUMERIC column holding the amount
+	protoMoney = "google.type.Money"
 )
 
 // ProtoReflectOptions configures how protobuf message reflection is performed
 // for generating AIP filter options.
+// Fields can also be configured in the proto file with the (pika.field) option
+// from pika/options.proto, which takes precedence over these options.
 type ProtoReflectOptions struct {
 	// Exclude is a list of field names to exclude from the filter
 	// Uses proto name always, not JSON name
+	// Nested fields are excluded by their path, for example spec.image
 	Exclude []string
 
 	// ColumnName is a function that returns the column name for a given field
 	// name. If not provided, the field name is used.
 	ColumnName func(string) string
+
+	// MaxDepth limits how deep ProtoReflect recurses into nested messages.
+	// Top level fields have a depth of 1. Defaults to 3.
+	MaxDepth int
+
+	// FieldMask limits FromProto and ToProto to the fields in the mask.
+	// Paths use proto names, "*" selects all fields.
+	// If nil, all fields are converted.
+	FieldMask *fieldmaskpb.FieldMask
+}
+
+// defaultProtoReflectDepth is the default depth of nested messages used by ProtoReflect
+const defaultProtoReflectDepth = 3
+
+// protoParent describes the message field that contains nested fields
+type protoParent struct {
+	// name is the identifier prefix, for example "spec."
+	name string
+	// path is the proto name prefix used for Exclude, for example "spec."
+	path string
+	// column is the prefix of flattened columns, for example "spec_",
+	// or the JSON path of the parent if json is set, for example "spec->"
+	column string
+	// json is true if the parent is stored in a JSONB column
+	json bool
+	// depth is the depth of the parent, 0 for the top level message
+	depth int
 }
 
 func protoReflect(m proto.Message, opts ProtoReflectOptions) AIPFilterOptions {
 	res := AIPFilterOptions{
 		Identifiers:           map[string]AIPFilterIdentifier{},
 		AcceptableIdentifiers: []string{},
+		SortableIdentifiers:   []string{},
+		restricted:            true,
+	}
+
+	if opts.MaxDepth == 0 {
+		opts.MaxDepth = defaultProtoReflectDepth
 	}
+	protoReflectMessage(&res, m.ProtoReflect().Descriptor(), opts, protoParent{})
+
+	return res
+}
 
-	fields := m.ProtoReflect().Descriptor().Fields()
+func protoReflectMessage(res *AIPFilterOptions, desc protoreflect.MessageDescriptor, opts ProtoReflectOptions, parent protoParent) {
+	fields := desc.Fields()
+
+	// If any field is marked filterable or sortable, only marked fields are used
+	annotated := false
+	for i := range fields.Len() {
+		fieldOpts, _ := proto.GetExtension(fields.Get(i).Options(), pikapb.E_Field).(*pikapb.FieldOptions)
+		if fieldOpts.GetFilterable() || fieldOpts.GetSortable() {
+			annotated = true
+			break
+		}
+	}
+
+	// Members of oneofs are regular fields, so they are handled like any other field
 	for i := range fields.Len() {
 		// Get field from message
 		fd := fields.Get(i)
 
-		// Get name, use JSON name if available
-		name := string(fd.Name())
+		fieldOpts, _ := proto.GetExtension(fd.Options(), pikapb.E_Field).(*pikapb.FieldOptions)
+		filterable := !annotated || fieldOpts.GetFilterable()
+		sortable := !annotated || fieldOpts.GetSortable()
+		if !filterable && !sortable {
+			continue
+		}
+
 		// Skip if excluded
-		if contains(opts.Exclude, name) {
+		path := parent.path + string(fd.Name())
+		if contains(opts.Exclude, path) {
 			continue
 		}
+
+		// Get name, use JSON name if available
+		name := string(fd.Name())
 		if fd.JSONName() != "" {
 			name = fd.JSONName()
 		}
 
+		// Nested fields are in flattened columns or in the JSONB column of the parent
+		column := parent.column + protoColumnName(fd, opts)
+		if parent.json {
+			column = parent.column + name
+			if fieldOpts.GetColumn() != "" {
+				column = parent.column + fieldOpts.GetColumn()
+			}
+		}
+		name = parent.name + name
+
 		// Now let's configure the identifiers map with acceptable value and
 		// potential aliases for enum
 		ident := AIPFilterIdentifier{
@@ -74,80 +187,244 @@ func protoReflect(m proto.Message, opts ProtoReflectOptions) AIPFilterOptions {
 			AcceptedTypes:  []int{},
 			AcceptedValues: []any{},
 			IsRepeated:     false,
+			ColumnName:     column,
 		}
 
-		// Check if repeated
-		if fd.Cardinality() == protoreflect.Repeated {
-			ident.IsRepeated = true
-		}
-
-		// Check and add type
-		// If type is message and not a supported wrapper type, skip
-		switch fd.Kind() {
-		case protoreflect.StringKind,
-			protoreflect.FloatKind,
-			protoreflect.Int32Kind,
-			protoreflect.Int64Kind,
-			protoreflect.Uint32Kind,
-			protoreflect.Uint64Kind:
-			ident.AcceptedTypes = append(ident.AcceptedTypes, protoKindToLexer[fd.Kind()])
-		case protoreflect.BoolKind:
-			ident.AcceptedTypes = append(ident.AcceptedTypes, parser.FilterLexerTRUE, parser.FilterLexerFALSE)
-		case protoreflect.MessageKind:
-			// Bool wrappers need two types
-			if fd.Message().FullName() == "google.protobuf.BoolValue" {
-				ident.AcceptedTypes = append(ident.AcceptedTypes, parser.FilterLexerTRUE, parser.FilterLexerFALSE, parser.FilterLexerNULL)
-			} else {
-				// Check if it's a supported wrapper type
-				if lexer, ok := protoMessageKindToLexer[string(fd.Message().FullName())]; ok {
-					ident.AcceptedTypes = append(ident.AcceptedTypes, lexer)
-
-					// All wrappers can be nil
-					ident.AcceptedTypes = append(ident.AcceptedTypes, parser.FilterLexerNULL)
-				} else {
-					// Skip this field
+		switch {
+		case fd.IsMap():
+			// Maps are filtered by key, for example labels.env, so they can't be sorted
+			if fd.MapKey().Kind() != protoreflect.StringKind || protoJSONObject(fd.MapValue()) || !protoIdentifierType(&ident, fd.MapValue()) {
+				continue
+			}
+			protoJSONValue(&ident, fd.MapValue())
+			ident.IsMap = true
+			sortable = false
+		case protoNestedMessage(fd):
+			// Repeated messages can't be filtered, and recursive messages stop at the depth limit
+			if fd.IsList() || parent.depth+2 > opts.MaxDepth {
+				continue
+			}
+
+			nested := protoParent{
+				name:   name + ".",
+				path:   path + ".",
+				column: column + "_",
+				json:   parent.json || fieldOpts.GetJsonb(),
+				depth:  parent.depth + 1,
+			}
+			if nested.json {
+				nested.column = column + "->"
+			}
+			protoReflectMessage(res, fd.Message(), opts, nested)
+			continue
+		default:
+			if !protoIdentifierType(&ident, fd) {
+				continue
+			}
+
+			// Durations are compared as nanoseconds, unless the column is an INTERVAL
+			if fieldOpts.GetInterval() && ident.AcceptedTypes[0] == parser.FilterLexerDURATION {
+				ident.ConvertValue = protoInterval
+			}
+
+			// Dates and money are objects in JSON, so they can't be compared
+			if parent.json && protoJSONObject(fd) {
+				continue
+			}
+
+			// Check if repeated
+			if fd.Cardinality() == protoreflect.Repeated {
+				// JSON arrays can't be filtered
+				if parent.json {
 					continue
 				}
+				ident.IsRepeated = true
 			}
-		case protoreflect.EnumKind:
-			// Add all enum values as aliases
-			for i := range fd.Enum().Values().Len() {
-				enumName := string(fd.Enum().Values().Get(i).Name())
-				enumReverseSplit := strings.Split(enumName, "_")
-				for i := range len(enumReverseSplit) / 2 {
-					j := len(enumReverseSplit) - i - 1
-					enumReverseSplit[i], enumReverseSplit[j] = enumReverseSplit[j], enumReverseSplit[i]
-				}
-				lastValue := enumReverseSplit[0]
-
-				// Both the name and the last value are acceptable
-				// Only add last value if it's not the same as the name
-				num := int64(fd.Enum().Values().Get(i).Number())
-				ident.ValueAliases[strings.ToLower(enumName)] = num
-				if lastValue != enumName {
-					ident.ValueAliases[strings.ToLower(lastValue)] = num
-				}
 
-				// Add num as acceptable value
-				ident.AcceptedValues = append(ident.AcceptedValues, num)
+			if parent.json {
+				protoJSONValue(&ident, fd)
 			}
-			// Add the enum value as a type
+		}
+
+		// JSON paths can't be sorted
+		if parent.json {
+			sortable = false
+		}
+
+		// Add to identifiers, aliases share the configuration of the field
+		for _, identifier := range append([]string{name}, fieldOpts.GetAliases()...) {
+			if identifier != name {
+				identifier = parent.name + identifier
+			}
+
+			res.Identifiers[identifier] = ident
+			if filterable {
+				res.AcceptableIdentifiers = append(res.AcceptableIdentifiers, identifier)
+			}
+			if sortable {
+				res.SortableIdentifiers = append(res.SortableIdentifiers, identifier)
+			}
+		}
+	}
+}
+
+// protoNestedMessage returns true if the field is a message that ProtoReflect recurses into.
+// Wrappers and other well-known types are not nested messages.
+func protoNestedMessage(fd protoreflect.FieldDescriptor) bool {
+	if fd.Kind() != protoreflect.MessageKind {
+		return false
+	}
+
+	name := fd.Message().FullName()
+	return fd.Message().ParentFile().Package() != "google.protobuf" && name != protoDate && name != protoMoney
+}
+
+// protoJSONObject returns true if the field is a well-known type that is an object in JSON
+func protoJSONObject(fd protoreflect.FieldDescriptor) bool {
+	if fd.Kind() != protoreflect.MessageKind {
+		return false
+	}
+
+	name := fd.Message().FullName()
+	return name == protoDate || name == protoMoney
+}
+
+// protoIdentifierType sets the accepted types of an identifier from the kind of a field.
+// Returns false if the field can't be filtered.
+func protoIdentifierType(ident *AIPFilterIdentifier, fd protoreflect.FieldDescriptor) bool {
+	// Check and add type
+	// If type is message and not a supported wrapper type, skip
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		ident.AcceptedTypes = append(ident.AcceptedTypes, parser.FilterLexerTRUE, parser.FilterLexerFALSE)
+	case protoreflect.MessageKind:
+		name := string(fd.Message().FullName())
+		if contains(protoUnsupportedMessages, name) {
+			return false
+		}
+
+		// Bool wrappers need two types
+		if name == "google.protobuf.BoolValue" {
+			ident.AcceptedTypes = append(ident.AcceptedTypes, parser.FilterLexerTRUE, parser.FilterLexerFALSE, parser.FilterLexerNULL)
+			break
+		}
+
+		// Check if it's a supported wrapper type
+		lexer, ok := protoMessageKindToLexer[name]
+		if !ok {
+			return false
+		}
+		ident.AcceptedTypes = append(ident.AcceptedTypes, lexer)
+
+		switch name {
+		case protoDate:
+			ident.ConvertValue = protoDateValue
+		case protoMoney:
+			// Whole amounts don't need a decimal point
 			ident.AcceptedTypes = append(ident.AcceptedTypes, parser.FilterLexerNUM_INT)
 		}
 
-		// Add column name
-		if opts.ColumnName != nil {
-			ident.ColumnName = opts.ColumnName(name)
-		} else {
-			ident.ColumnName = strcase.ToSnake(name)
+		// All wrappers can be nil
+		ident.AcceptedTypes = append(ident.AcceptedTypes, parser.FilterLexerNULL)
+	case protoreflect.EnumKind:
+		// Add all enum values as aliases
+		values := fd.Enum().Values()
+		for i := range values.Len() {
+			ident.addEnumValue(string(values.Get(i).Name()), int64(values.Get(i).Number()))
+		}
+		// Add the enum value as a type
+		ident.AcceptedTypes = append(ident.AcceptedTypes, parser.FilterLexerNUM_INT)
+	default:
+		lexer, ok := protoKindToLexer[fd.Kind()]
+		if !ok {
+			return false
 		}
+		ident.AcceptedTypes = append(ident.AcceptedTypes, lexer)
+	}
+
+	return true
+}
 
-		// Add to identifiers
-		res.Identifiers[name] = ident
-		res.AcceptableIdentifiers = append(res.AcceptableIdentifiers, name)
+// protoJSONValue configures an identifier of a JSON value in a JSONB column.
+// JSON paths are extracted as text, so numbers, bools, timestamps and durations are cast,
+// and enums are compared by name, as protojson stores the name.
+func protoJSONValue(ident *AIPFilterIdentifier, fd protoreflect.FieldDescriptor) {
+	if fd.Kind() == protoreflect.EnumKind {
+		ident.ConvertValue = protoEnumName(fd.Enum())
+		return
 	}
 
-	return res
+	if cast, ok := protoJSONCasts[ident.AcceptedTypes[0]]; ok {
+		ident.ColumnName += "::" + cast
+	}
+
+	// Durations are cast to intervals
+	if ident.AcceptedTypes[0] == parser.FilterLexerDURATION {
+		ident.ConvertValue = protoInterval
+	}
+}
+
+// protoEnumName converts the number of an enum value to its name.
+// Unknown numbers are already rejected by the accepted values.
+func protoEnumName(ed protoreflect.EnumDescriptor) func(value any) (any, error) {
+	return func(value any) (any, error) {
+		number, ok := value.(int64)
+		if !ok {
+			return value, nil
+		}
+
+		if v := ed.Values().ByNumber(protoreflect.EnumNumber(number)); v != nil {
+			return string(v.Name()), nil
+		}
+
+		return value, nil
+	}
+}
+
+// protoInterval formats a duration as an interval, for fields with the interval option and JSON values.
+// PostgreSQL intervals have a precision of microseconds.
+func protoInterval(value any) (any, error) {
+	d, ok := value.(time.Duration)
+	if !ok {
+		return value, nil
+	}
+
+	return fmt.Sprintf("%d microseconds", d.Microseconds()), nil
+}
+
+// protoDateValue validates a date in the form of YYYY-MM-DD
+func protoDateValue(value any) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.Format(time.DateOnly), nil
+}
+
+// protoColumnName returns the column of a field.
+// The column from the (pika.field) option is used if set, otherwise
+// ColumnName or the snake cased JSON name of the field.
+func protoColumnName(fd protoreflect.FieldDescriptor, opts ProtoReflectOptions) string {
+	fieldOpts, _ := proto.GetExtension(fd.Options(), pikapb.E_Field).(*pikapb.FieldOptions)
+	if column := fieldOpts.GetColumn(); column != "" {
+		return column
+	}
+
+	name := string(fd.Name())
+	if fd.JSONName() != "" {
+		name = fd.JSONName()
+	}
+	if opts.ColumnName != nil {
+		return opts.ColumnName(name)
+	}
+
+	return strcase.ToSnake(name)
 }
 
 // ProtoReflect generates AIP filter options from a protobuf message using default settings.
@@ -163,3 +440,16 @@ func ProtoReflect(m proto.Message) AIPFilterOptions {
 func ProtoReflectWithOpts(m proto.Message, opts ProtoReflectOptions) AIPFilterOptions {
 	return protoReflect(m, opts)
 }
+
+// ProtoTableName returns the table set with the (pika.resource) option of a protobuf message,
+// or an empty string if the message has no table.
+// FromProto, ToProto, UpdateMask and WithReadMask return ErrProtoTableMismatch
+// if the table is not the table of the model.
+func ProtoTableName(m proto.Message) string {
+	return protoTableName(m.ProtoReflect().Descriptor())
+}
+
+func protoTableName(desc protoreflect.MessageDescriptor) string {
+	resourceOpts, _ := proto.GetExtension(desc.Options(), pikapb.E_Resource).(*pikapb.ResourceOptions)
+	return resourceOpts.GetTable()
+}
@@ -4,7 +4,9 @@
 package pika
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -14,6 +16,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.ciq.dev/pika/parser"
 	pikatestpb "go.ciq.dev/pika/testproto"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type protoModel4 struct {
@@ -192,13 +196,13 @@ func TestComplete3AIP160(t *testing.T) {
 	qs = Q[protoModel4](psql)
 	_, err = qs.AIP160(filter, opts)
 	require.NotNil(t, err)
-	require.Equal(t, "type is not accepted for identifier: STRING for identifier status", err.Error())
+	require.Equal(t, "type is not accepted for identifier: STRING for identifier status (valid values: STATUS_UNSPECIFIED, STATUS_OK, STATUS_ERROR, STATUS_CANCELED, STATUS_TEST2, STATUS_TEST3, STATUS_TEST4)", err.Error())
 
 	filter = `status = 99`
 	qs = Q[protoModel4](psql)
 	_, err = qs.AIP160(filter, opts)
 	require.NotNil(t, err)
-	require.Equal(t, "value is not accepted for identifier: 99 for identifier status", err.Error())
+	require.Equal(t, "value is not accepted for identifier: 99 for identifier status (valid values: STATUS_UNSPECIFIED, STATUS_OK, STATUS_ERROR, STATUS_CANCELED, STATUS_TEST2, STATUS_TEST3, STATUS_TEST4)", err.Error())
 }
 
 func TestComplete3Exclude(t *testing.T) {
@@ -280,3 +284,356 @@ func TestArrayNotHas(t *testing.T) {
 	require.Equal(t, expectedQuery, actualQuery)
 	require.Equal(t, expectedArgs, actualArgs)
 }
+
+func TestAnnotated4ProtoReflect(t *testing.T) {
+	opts := ProtoReflect(&pikatestpb.Annotated4{})
+
+	require.Equal(t, []string{"title", "name", "description", "createTime"}, opts.AcceptableIdentifiers)
+	require.Equal(t, []string{"id", "title", "name", "createTime"}, opts.SortableIdentifiers)
+	require.Equal(t, "title", opts.Identifiers["name"].ColumnName)
+	require.Equal(t, "created_at", opts.Identifiers["createTime"].ColumnName)
+	require.NotContains(t, opts.Identifiers, "internal")
+
+	require.Equal(t, "simple_model_1", ProtoTableName(&pikatestpb.Annotated4{}))
+	require.Equal(t, "", ProtoTableName(&pikatestpb.Simple1{}))
+}
+
+func TestAnnotated4OrderBy(t *testing.T) {
+	opts := ProtoReflect(&pikatestpb.Annotated4{})
+
+	orderBy, err := opts.verifyOrderBy("createTime desc,name")
+	require.Nil(t, err)
+	require.Equal(t, []string{"-created_at", "title"}, orderBy)
+
+	// Filterable, but not sortable
+	_, err = opts.verifyOrderBy("description")
+	require.ErrorIs(t, err, ErrIdentifierNotAcceptable)
+
+	// Without annotations, all fields are sortable
+	orderBy, err = ProtoReflect(&pikatestpb.Complete3{}).verifyOrderBy("nullableInt desc")
+	require.Nil(t, err)
+	require.Equal(t, []string{"-nullable_int"}, orderBy)
+}
+
+func TestAnnotated4Filter(t *testing.T) {
+	opts := ProtoReflect(&pikatestpb.Annotated4{})
+
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	filter := `name = "Test" AND description = "Test"`
+	qs := Q[simpleModel1](psql)
+	qs, err := qs.AIP160(filter, opts)
+	require.Nil(t, err)
+
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."title" = $1 AND "simpleModel1"."description" = $2)`
+	expectedArgs := []interface{}{"Test", "Test"}
+	actualQuery, actualArgs := qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+	require.Equal(t, expectedArgs, actualArgs)
+
+	// Sortable, but not filterable
+	filter = `id = 1`
+	qs = Q[simpleModel1](psql)
+	_, err = qs.AIP160(filter, opts)
+	require.ErrorIs(t, err, ErrIdentifierNotAllowed)
+}
+
+func TestEmptyAcceptableIdentifiers(t *testing.T) {
+	psql := newPsql(t)
+
+	// An empty list allows all identifiers, like nil
+	qs, err := Q[simpleModel1](psql).AIP160(`title = "Test"`, AIPFilterOptions{
+		AcceptableIdentifiers: []string{},
+	})
+	require.Nil(t, err)
+
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."title" = $1)`
+	actualQuery, _ := qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	// Messages without filterable fields don't allow any identifier
+	opts := ProtoReflectWithOpts(&pikatestpb.Simple1{}, ProtoReflectOptions{Exclude: []string{"name"}})
+	require.Empty(t, opts.AcceptableIdentifiers)
+	_, err = Q[simpleModel1](psql).AIP160(`title = "Test"`, opts)
+	require.ErrorIs(t, err, ErrIdentifierNotAllowed)
+}
+
+type nested5Model struct {
+	PikaTableName string `pika:"nested_5"`
+
+	ID                 int             `db:"id"`
+	Name               string          `db:"name"`
+	SpecContainerImage string          `db:"spec_container_image"`
+	Metadata           json.RawMessage `db:"metadata"`
+}
+
+func TestNested5ProtoReflect(t *testing.T) {
+	opts := ProtoReflectWithOpts(&pikatestpb.Nested5{}, ProtoReflectOptions{MaxDepth: 2})
+
+	require.Equal(t, []string{
+		"name",
+		"spec.replicas",
+		"metadata.labels",
+		"metadata.createTime",
+		"metadata.status",
+		"metadata.deleted",
+		"counters",
+		"url",
+		"container.image",
+		"container.env",
+		"parent.name",
+		"parent.counters",
+		"parent.url",
+	}, opts.AcceptableIdentifiers)
+	require.Equal(t, []string{"name", "spec.replicas", "url", "container.image", "parent.name", "parent.url"}, opts.SortableIdentifiers)
+
+	require.Equal(t, "spec_replicas", opts.Identifiers["spec.replicas"].ColumnName)
+	require.Equal(t, "metadata->labels", opts.Identifiers["metadata.labels"].ColumnName)
+	require.True(t, opts.Identifiers["metadata.labels"].IsMap)
+	require.Equal(t, "metadata->createTime::timestamptz", opts.Identifiers["metadata.createTime"].ColumnName)
+	require.Equal(t, "metadata->deleted::boolean", opts.Identifiers["metadata.deleted"].ColumnName)
+	require.Equal(t, "metadata->status", opts.Identifiers["metadata.status"].ColumnName)
+	require.Equal(t, "counters::numeric", opts.Identifiers["counters"].ColumnName)
+	require.Equal(t, "container_env", opts.Identifiers["container.env"].ColumnName)
+	require.Equal(t, []int{parser.FilterLexerNUM_INT}, opts.Identifiers["counters"].AcceptedTypes)
+
+	// The default depth is 3
+	opts = ProtoReflect(&pikatestpb.Nested5{})
+	require.Equal(t, "spec_container_image", opts.Identifiers["spec.container.image"].ColumnName)
+	require.Contains(t, opts.Identifiers, "parent.parent.name")
+	require.NotContains(t, opts.Identifiers, "parent.spec.container.image")
+
+	opts = ProtoReflectWithOpts(&pikatestpb.Nested5{}, ProtoReflectOptions{Exclude: []string{"spec.container"}})
+	require.Contains(t, opts.Identifiers, "spec.replicas")
+	require.NotContains(t, opts.Identifiers, "spec.container.image")
+}
+
+func TestNested5AIP160(t *testing.T) {
+	opts := ProtoReflect(&pikatestpb.Nested5{})
+	psql := newPsql(t)
+
+	filter := `spec.container.image = "rocky" AND metadata.labels.env = prod AND counters.restarts > 2`
+	qs, err := Q[nested5Model](psql).AIP160(filter, opts)
+	require.Nil(t, err)
+
+	expectedQuery := `SELECT "nested5Model"."id", "nested5Model"."name", "nested5Model"."spec_container_image", "nested5Model"."metadata" FROM "nested_5" "nested5Model" WHERE ("nested5Model"."spec_container_image" = $1 AND "nested5Model"."metadata"->'labels'->>'env' = $2 AND ("nested5Model"."counters"->>'restarts')::numeric > $3)`
+	expectedArgs := []interface{}{"rocky", "prod", int64(2)}
+	actualQuery, actualArgs := qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+	require.Equal(t, expectedArgs, actualArgs)
+
+	// JSON values are cast, and enums are compared by name
+	filter = `metadata.createTime > 2022-01-01T00:00:00Z AND metadata.deleted = true AND metadata.status = STATUS_OK`
+	qs, err = Q[nested5Model](psql).AIP160(filter, opts)
+	require.Nil(t, err)
+
+	expectedQuery = `SELECT "nested5Model"."id", "nested5Model"."name", "nested5Model"."spec_container_image", "nested5Model"."metadata" FROM "nested_5" "nested5Model" WHERE (("nested5Model"."metadata"->>'createTime')::timestamptz > $1 AND ("nested5Model"."metadata"->>'deleted')::boolean = $2 AND "nested5Model"."metadata"->>'status' = $3)`
+	expectedArgs = []interface{}{time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), true, "STATUS_OK"}
+	actualQuery, actualArgs = qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+	require.Equal(t, expectedArgs, actualArgs)
+
+	_, err = Q[nested5Model](psql).AIP160(`metadata.status = 9`, opts)
+	require.ErrorIs(t, err, ErrValueNotAccepted)
+
+	// Maps can only be filtered by key
+	_, err = Q[nested5Model](psql).AIP160(`metadata.labels = "prod"`, opts)
+	require.ErrorIs(t, err, ErrIdentifierNotAllowed)
+
+	_, err = Q[nested5Model](psql).AIP160(`sidecars.image = "rocky"`, opts)
+	require.ErrorIs(t, err, ErrIdentifierNotAllowed)
+
+	_, err = Q[nested5Model](psql).AIP160(`spec..replicas = 1`, opts)
+	require.ErrorIs(t, err, ErrUnexpectedToken)
+
+	// Nested fields in flattened columns can be sorted, JSON paths can't
+	orderBy, err := opts.verifyOrderBy("spec.container.image desc")
+	require.Nil(t, err)
+	require.Equal(t, []string{"-spec_container_image"}, orderBy)
+
+	_, err = opts.verifyOrderBy("metadata.createTime")
+	require.ErrorIs(t, err, ErrIdentifierNotAcceptable)
+}
+
+func createTestEntries5(t *testing.T, psql *PostgreSQL) {
+	_, err := psql.db.Exec("DROP TABLE IF EXISTS nested_5")
+	require.Nil(t, err)
+
+	_, err = psql.db.Exec("CREATE TABLE nested_5 (id SERIAL PRIMARY KEY, name TEXT, spec_container_image TEXT, metadata JSONB, counters JSONB)")
+	require.Nil(t, err)
+
+	// JSONB columns hold protojson, where int64 values are strings and enums are names
+	rows := []struct {
+		metadata *pikatestpb.Metadata5
+		restarts int64
+	}{
+		{&pikatestpb.Metadata5{CreateTime: timestamppb.New(time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)), Status: pikatestpb.Status_STATUS_OK, Deleted: true}, 10},
+		{&pikatestpb.Metadata5{CreateTime: timestamppb.New(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)), Status: pikatestpb.Status_STATUS_ERROR}, 2},
+	}
+	for i, row := range rows {
+		metadata, err := protojson.Marshal(row.metadata)
+		require.Nil(t, err)
+
+		_, err = psql.db.Exec(
+			"INSERT INTO nested_5 (id, name, metadata, counters) VALUES ($1, $2, $3, $4)",
+			i+1, fmt.Sprintf("Test%d", i+1), string(metadata), fmt.Sprintf(`{"restarts": "%d"}`, row.restarts),
+		)
+		require.Nil(t, err)
+	}
+}
+
+func TestNested5Compare(t *testing.T) {
+	opts := ProtoReflect(&pikatestpb.Nested5{})
+	psql := newPsql(t)
+	createTestEntries5(t, psql)
+
+	for filter, expected := range map[string]int{
+		// 10 > 2 as numbers, not as text
+		`counters.restarts > 2`:                      1,
+		`metadata.createTime > 2022-01-01T00:00:00Z`: 1,
+		`metadata.deleted = true`:                    1,
+		`metadata.status = STATUS_ERROR`:             2,
+	} {
+		qs, err := Q[nested5Model](psql).AIP160(filter, opts)
+		require.Nil(t, err, filter)
+
+		ret, err := qs.All(context.Background())
+		require.Nil(t, err, filter)
+		require.Len(t, ret, 1, filter)
+		require.Equal(t, expected, ret[0].ID, filter)
+	}
+}
+
+type kinds6Model struct {
+	PikaTableName string `pika:"kinds_6"`
+
+	ID        int            `db:"id"`
+	Double    float64        `db:"double"`
+	Bytes     []byte         `db:"bytes"`
+	Timeout   sql.NullString `db:"timeout"`
+	BirthDate sql.NullTime   `db:"birth_date"`
+	Price     sql.NullString `db:"price"`
+}
+
+func createTestEntries6(t *testing.T, psql *PostgreSQL) {
+	_, err := psql.db.Exec("DROP TABLE IF EXISTS kinds_6")
+	require.Nil(t, err)
+
+	_, err = psql.db.Exec(`CREATE TABLE kinds_6 (id SERIAL PRIMARY KEY, "double" DOUBLE PRECISION NOT NULL, bytes BYTEA, timeout INTERVAL, birth_date DATE, price NUMERIC)`)
+	require.Nil(t, err)
+
+	_, err = psql.db.Exec(`
+		INSERT INTO kinds_6 (id, "double", bytes, timeout, birth_date, price)
+		VALUES
+		(1, 1.5, 'abc', '30 seconds', '1990-05-01', 9.99),
+		(2, 2.5, 'def', '5 minutes', '2001-12-31', 100),
+		(3, 3.5, NULL, NULL, NULL, NULL)
+	`)
+	require.Nil(t, err)
+}
+
+func TestKinds6ProtoReflect(t *testing.T) {
+	opts := ProtoReflect(&pikatestpb.Kinds6{})
+
+	// FieldMask, Any and Struct can't be filtered
+	require.Equal(t, []string{
+		"double",
+		"float",
+		"sint32",
+		"sint64",
+		"fixed32",
+		"fixed64",
+		"sfixed32",
+		"sfixed64",
+		"bytes",
+		"bool",
+		"nullableDouble",
+		"nullableBytes",
+		"timeout",
+		"birthDate",
+		"price",
+		"retention",
+	}, opts.AcceptableIdentifiers)
+
+	require.Equal(t, []int{parser.FilterLexerNUM_FLOAT}, opts.Identifiers["double"].AcceptedTypes)
+	require.Equal(t, []int{parser.FilterLexerNUM_INT}, opts.Identifiers["sint32"].AcceptedTypes)
+	require.Equal(t, []int{parser.FilterLexerNUM_INT}, opts.Identifiers["sfixed64"].AcceptedTypes)
+	require.Equal(t, []int{parser.FilterLexerNUM_UINT}, opts.Identifiers["fixed32"].AcceptedTypes)
+	require.Equal(t, []int{parser.FilterLexerSTRING}, opts.Identifiers["bytes"].AcceptedTypes)
+	require.Equal(t, []int{parser.FilterLexerTRUE, parser.FilterLexerFALSE}, opts.Identifiers["bool"].AcceptedTypes)
+	require.Equal(t, []int{parser.FilterLexerNUM_FLOAT, parser.FilterLexerNULL}, opts.Identifiers["nullableDouble"].AcceptedTypes)
+	require.Equal(t, []int{parser.FilterLexerSTRING, parser.FilterLexerNULL}, opts.Identifiers["nullableBytes"].AcceptedTypes)
+	require.Equal(t, []int{parser.FilterLexerDURATION, parser.FilterLexerNULL}, opts.Identifiers["timeout"].AcceptedTypes)
+	require.Equal(t, []int{parser.FilterLexerSTRING, parser.FilterLexerNULL}, opts.Identifiers["birthDate"].AcceptedTypes)
+	require.Equal(t, []int{parser.FilterLexerNUM_FLOAT, parser.FilterLexerNUM_INT, parser.FilterLexerNULL}, opts.Identifiers["price"].AcceptedTypes)
+
+	// Dates and money are values, not nested messages
+	require.Equal(t, "birth_date", opts.Identifiers["birthDate"].ColumnName)
+	require.NotContains(t, opts.Identifiers, "birthDate.year")
+	require.NotContains(t, opts.Identifiers, "price.units")
+}
+
+func TestKinds6AIP160(t *testing.T) {
+	opts := ProtoReflect(&pikatestpb.Kinds6{})
+	psql := newPsql(t)
+
+	filter := `double > 1.5 AND bytes = "abc" AND timeout > 90.5s AND birthDate >= "2000-01-01" AND price < 10`
+	qs, err := Q[kinds6Model](psql).AIP160(filter, opts)
+	require.Nil(t, err)
+
+	expectedQuery := `SELECT "kinds6Model"."id", "kinds6Model"."double", "kinds6Model"."bytes", "kinds6Model"."timeout", "kinds6Model"."birth_date", "kinds6Model"."price" FROM "kinds_6" "kinds6Model" WHERE ("kinds6Model"."double" > $1 AND "kinds6Model"."bytes" = $2 AND "kinds6Model"."timeout" > $3 AND "kinds6Model"."birth_date" >= $4 AND "kinds6Model"."price" < $5)`
+	expectedArgs := []interface{}{1.5, "abc", "90500000 microseconds", "2000-01-01", int64(10)}
+	actualQuery, actualArgs := qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+	require.Equal(t, expectedArgs, actualArgs)
+
+	// Durations without the interval option are compared as nanoseconds
+	qs, err = Q[kinds6Model](psql).AIP160(`retention > 3600s`, opts)
+	require.Nil(t, err)
+
+	expectedQuery = `SELECT "kinds6Model"."id", "kinds6Model"."double", "kinds6Model"."bytes", "kinds6Model"."timeout", "kinds6Model"."birth_date", "kinds6Model"."price" FROM "kinds_6" "kinds6Model" WHERE ("kinds6Model"."retention" > $1)`
+	actualQuery, actualArgs = qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+	require.Equal(t, []interface{}{time.Hour}, actualArgs)
+
+	_, err = Q[kinds6Model](psql).AIP160(`birthDate = "2000-13-01"`, opts)
+	require.ErrorIs(t, err, ErrValueNotAccepted)
+
+	_, err = Q[kinds6Model](psql).AIP160(`double = 1`, opts)
+	require.ErrorIs(t, err, ErrTypeNotAccepted)
+
+	_, err = Q[kinds6Model](psql).AIP160(`mask = "title"`, opts)
+	require.ErrorIs(t, err, ErrIdentifierNotAllowed)
+
+	_, err = Q[kinds6Model](psql).AIP160(`details = "x"`, opts)
+	require.ErrorIs(t, err, ErrIdentifierNotAllowed)
+}
+
+func TestKinds6Compare(t *testing.T) {
+	opts := ProtoReflect(&pikatestpb.Kinds6{})
+	psql := newPsql(t)
+	createTestEntries6(t, psql)
+	ctx := context.Background()
+
+	// Durations are compared as intervals
+	ret, err := Q[kinds6Model](psql).AIP160(`timeout > 60s`, opts)
+	require.Nil(t, err)
+	all, err := ret.All(ctx)
+	require.Nil(t, err)
+	require.Len(t, all, 1)
+	require.Equal(t, 2, all[0].ID)
+
+	ret, err = Q[kinds6Model](psql).AIP160(`birthDate < "2000-01-01" OR price >= 100`, opts)
+	require.Nil(t, err)
+	all, err = ret.OrderBy("id").All(ctx)
+	require.Nil(t, err)
+	require.Len(t, all, 2)
+
+	ret, err = Q[kinds6Model](psql).AIP160(`timeout = null`, opts)
+	require.Nil(t, err)
+	all, err = ret.All(ctx)
+	require.Nil(t, err)
+	require.Len(t, all, 1)
+	require.Equal(t, 3, all[0].ID)
+}
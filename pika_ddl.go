@@ -0,0 +1,443 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// Static errors for err113 compliance
+var (
+	ErrUnsupportedType = errors.New("unsupported type")
+)
+
+// maxIdentifierLength is the maximum length of identifiers in PostgreSQL, longer names are truncated
+const maxIdentifierLength = 63
+
+// sqlTypes are the SQL types of Go types that are not inferred from the kind.
+// The bool is true if the type is nullable.
+var sqlTypes = map[reflect.Type]struct {
+	sqlType  string
+	nullable bool
+}{
+	reflect.TypeOf(time.Time{}):       {"TIMESTAMPTZ", false},
+	reflect.TypeOf(json.RawMessage{}): {"JSONB", false},
+	reflect.TypeOf([]byte{}):          {"BYTEA", false},
+	reflect.TypeOf(sql.NullString{}):  {"TEXT", true},
+	reflect.TypeOf(sql.NullInt64{}):   {"BIGINT", true},
+	reflect.TypeOf(sql.NullInt32{}):   {"INTEGER", true},
+	reflect.TypeOf(sql.NullInt16{}):   {"SMALLINT", true},
+	reflect.TypeOf(sql.NullByte{}):    {"SMALLINT", true},
+	reflect.TypeOf(sql.NullFloat64{}): {"DOUBLE PRECISION", true},
+	reflect.TypeOf(sql.NullBool{}):    {"BOOLEAN", true},
+	reflect.TypeOf(sql.NullTime{}):    {"TIMESTAMPTZ", true},
+	reflect.TypeOf(pq.NullTime{}):     {"TIMESTAMPTZ", true},
+	reflect.TypeOf(pq.StringArray{}):  {"TEXT[]", false},
+	reflect.TypeOf(pq.Int64Array{}):   {"BIGINT[]", false},
+	reflect.TypeOf(pq.Int32Array{}):   {"INTEGER[]", false},
+	reflect.TypeOf(pq.Float64Array{}): {"DOUBLE PRECISION[]", false},
+	reflect.TypeOf(pq.Float32Array{}): {"REAL[]", false},
+	reflect.TypeOf(pq.BoolArray{}):    {"BOOLEAN[]", false},
+	reflect.TypeOf(pq.ByteaArray{}):   {"BYTEA[]", false},
+}
+
+// ddlColumn is a column definition generated from a model column
+type ddlColumn struct {
+	name     string
+	sqlType  string
+	nullable bool
+	column   *ColumnInfo
+}
+
+// DDL returns the CREATE TABLE statement for the model T, followed by CREATE INDEX statements.
+// Column types are inferred from the Go types, unless set with type= in the pika tag.
+// Pointers and sql.Null* types are nullable, other columns are NOT NULL.
+// uint and uint64 are NUMERIC(20), as their values don't fit in BIGINT.
+// A single integer primary key with "omitempty" is a serial column.
+// Example:
+//
+//	ID        int64     `db:"id" pika:"pk,omitempty"`
+//	Email     string    `db:"email" pika:"unique"`
+//	Name      *string   `db:"name" pika:"index"`
+//	CreatedAt time.Time `db:"created_at" pika:"omitempty,default=NOW()"`
+func DDL[T any]() (string, error) {
+	info := Model[T]()
+	return modelDDL(info, info.TableName, quoteTableName(info.TableName))
+}
+
+func modelDDL(info *ModelInfo, tableName string, tableRef string) (string, error) {
+	columns, err := ddlColumns(info)
+	if err != nil {
+		return "", err
+	}
+
+	definitions := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		definitions = append(definitions, column.definition())
+	}
+	if len(info.PrimaryKey) > 0 {
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", quoteColumns(info.PrimaryKey)))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE TABLE %s (\n\t%s\n);", tableRef, strings.Join(definitions, ",\n\t"))
+	for _, column := range columns {
+		if column.column.Indexed {
+			fmt.Fprintf(&sb, "\n%s", createIndex(tableName, tableRef, column.name))
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// ddlColumns returns the column definitions of the model.
+// Columns selected from other tables are skipped.
+func ddlColumns(info *ModelInfo) ([]*ddlColumn, error) {
+	columns := make([]*ddlColumn, 0, len(info.Columns))
+	for _, column := range info.Columns {
+		if strings.Contains(column.PikaName, ".") {
+			continue
+		}
+
+		sqlType, nullable, err := goSQLType(column.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s.%s (%s)", err, info.Name, column.Field, column.Type)
+		}
+		if column.SQLType != "" {
+			sqlType = column.SQLType
+		}
+
+		ddl := &ddlColumn{
+			name:     column.Name,
+			sqlType:  sqlType,
+			nullable: nullable && !column.PrimaryKey,
+			column:   column,
+		}
+
+		// Integer keys generated by the database
+		if column.PrimaryKey && column.OmitEmpty && len(info.PrimaryKey) == 1 && column.SQLType == "" && column.Default == "" {
+			switch sqlType {
+			case "SMALLINT":
+				ddl.sqlType = "SMALLSERIAL"
+			case "INTEGER":
+				ddl.sqlType = "SERIAL"
+			case "BIGINT":
+				ddl.sqlType = "BIGSERIAL"
+			}
+		}
+
+		columns = append(columns, ddl)
+	}
+
+	return columns, nil
+}
+
+func (c *ddlColumn) definition() string {
+	definition := fmt.Sprintf("\"%s\" %s", c.name, c.sqlType)
+	if !c.nullable {
+		definition += " NOT NULL"
+	}
+	if c.column.Default != "" {
+		definition += " DEFAULT " + c.column.Default
+	}
+	if c.column.Unique {
+		definition += " UNIQUE"
+	}
+
+	return definition
+}
+
+// goSQLType returns the SQL type for the Go type t, and true if the type is nullable
+func goSQLType(t reflect.Type) (string, bool, error) {
+	if t.Kind() == reflect.Pointer {
+		sqlType, _, err := goSQLType(t.Elem())
+		return sqlType, true, err
+	}
+
+	if x, ok := sqlTypes[t]; ok {
+		return x.sqlType, x.nullable, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN", false, nil
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return "SMALLINT", false, nil
+	case reflect.Int32, reflect.Uint16:
+		return "INTEGER", false, nil
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "BIGINT", false, nil
+	case reflect.Uint, reflect.Uint64:
+		return "NUMERIC(20)", false, nil
+	case reflect.Float32:
+		return "REAL", false, nil
+	case reflect.Float64:
+		return "DOUBLE PRECISION", false, nil
+	case reflect.String:
+		return "TEXT", false, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "BYTEA", false, nil
+		}
+		// Only slices of scalar types are arrays, other slices are stored as JSON
+		sqlType, _, err := goSQLType(t.Elem())
+		if err == nil && t.Elem().Kind() != reflect.Pointer && !strings.HasSuffix(sqlType, "[]") && sqlType != "JSONB" {
+			return sqlType + "[]", false, nil
+		}
+		return "JSONB", false, nil
+	case reflect.Map, reflect.Struct:
+		return "JSONB", false, nil
+	default:
+		return "", false, ErrUnsupportedType
+	}
+}
+
+func createIndex(tableName string, tableRef string, column string) string {
+	return fmt.Sprintf("CREATE INDEX \"%s\" ON %s (\"%s\");", indexName(tableName, column), tableRef, column)
+}
+
+// indexName returns the name of the index on column, <table>_<column>_idx
+func indexName(tableName string, column string) string {
+	return identifierName(fmt.Sprintf("%s_%s_idx", unqualifiedTableName(tableName), column))
+}
+
+// uniqueName returns the name of the unique constraint on column, <table>_<column>_key
+func uniqueName(tableName string, column string) string {
+	return identifierName(fmt.Sprintf("%s_%s_key", unqualifiedTableName(tableName), column))
+}
+
+// identifierName returns name if it fits in a PostgreSQL identifier.
+// Longer names are truncated and end with a hash of the full name, so they stay unique
+// and match the name stored by PostgreSQL.
+func identifierName(name string) string {
+	if len(name) <= maxIdentifierLength {
+		return name
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name))
+	suffix := fmt.Sprintf("_%08x", h.Sum32())
+
+	return name[:maxIdentifierLength-len(suffix)] + suffix
+}
+
+func quoteColumns(columns []string) string {
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		quoted = append(quoted, fmt.Sprintf("\"%s\"", column))
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// dbColumn is a column from information_schema.columns
+type dbColumn struct {
+	Name     string         `db:"column_name"`
+	DataType string         `db:"data_type"`
+	UDTName  string         `db:"udt_name"`
+	Nullable string         `db:"is_nullable"`
+	Default  sql.NullString `db:"column_default"`
+	// Scale is the number of fractional digits of numeric columns, NULL if unconstrained
+	Scale sql.NullInt64 `db:"numeric_scale"`
+}
+
+// dbTable is a table loaded from the database
+type dbTable struct {
+	schema  string
+	name    string
+	ref     string
+	columns map[string]*dbColumn
+	indexes []string
+	// unique are the columns with a single column unique constraint or index
+	unique []string
+}
+
+// loadTable loads the columns and indexes of the table of a model.
+// Columns is empty if the table doesn't exist.
+func (p *PostgreSQL) loadTable(ctx context.Context, info *ModelInfo) (*dbTable, error) {
+	table := &dbTable{
+		schema:  p.defaultSchema,
+		name:    info.TableName,
+		columns: map[string]*dbColumn{},
+	}
+	if !info.explicitTableName {
+		if x, ok := p.tableAlias[info.Name]; ok {
+			table.name = x
+		}
+	}
+	if schema, name, ok := strings.Cut(table.name, "."); ok {
+		table.schema, table.name = schema, name
+	}
+	table.ref = quoteTableName(table.name)
+	if table.schema != "" {
+		table.ref = quoteTableName(table.schema + "." + table.name)
+	}
+
+	var columns []*dbColumn
+	err := p.DB().SelectContext(ctx, &columns, `SELECT column_name, data_type, udt_name, is_nullable, column_default, numeric_scale
+		FROM information_schema.columns
+		WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2
+		ORDER BY ordinal_position`, table.schema, table.name)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range columns {
+		table.columns[column.Name] = column
+	}
+
+	err = p.DB().SelectContext(ctx, &table.indexes, `SELECT indexname FROM pg_indexes
+		WHERE schemaname = COALESCE(NULLIF($1, ''), current_schema()) AND tablename = $2`, table.schema, table.name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.DB().SelectContext(ctx, &table.unique, `SELECT a.attname FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = i.indkey[0]
+		WHERE i.indisunique AND i.indnkeyatts = 1 AND i.indpred IS NULL
+		AND n.nspname = COALESCE(NULLIF($1, ''), current_schema()) AND c.relname = $2`, table.schema, table.name)
+	if err != nil {
+		return nil, err
+	}
+
+	return table, nil
+}
+
+// Diff compares the models with the tables in the database, and returns the statements
+// needed to update the database to match the models.
+// Missing tables are created, missing columns, indexes and unique constraints are added, and the type,
+// nullability and missing defaults of existing columns are changed.
+// Columns that only exist in the database are ignored, as models can select a subset of the columns.
+// Models are passed as values or pointers, for example Diff(ctx, User{}, &Group{}).
+func (p *PostgreSQL) Diff(ctx context.Context, models ...any) ([]string, error) {
+	var statements []string
+	for _, model := range models {
+		t := reflect.TypeOf(model)
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		info := modelOf(t)
+
+		table, err := p.loadTable(ctx, info)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(table.columns) == 0 {
+			ddl, err := modelDDL(info, table.name, table.ref)
+			if err != nil {
+				return nil, err
+			}
+			statements = append(statements, ddl)
+			continue
+		}
+
+		columns, err := ddlColumns(info)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, column := range columns {
+			statements = append(statements, column.diff(table)...)
+			if column.column.Indexed && !contains(table.indexes, indexName(table.name, column.name)) {
+				statements = append(statements, createIndex(table.name, table.ref, column.name))
+			}
+		}
+	}
+
+	return statements, nil
+}
+
+// diff returns the statements needed to update the column in the database
+func (c *ddlColumn) diff(table *dbTable) []string {
+	alter := fmt.Sprintf("ALTER TABLE %s", table.ref)
+
+	existing, ok := table.columns[c.name]
+	if !ok {
+		return []string{fmt.Sprintf("%s ADD COLUMN %s;", alter, c.definition())}
+	}
+
+	var statements []string
+	if !existing.hasType(c.sqlType) {
+		sqlType := strings.NewReplacer("SMALLSERIAL", "SMALLINT", "BIGSERIAL", "BIGINT", "SERIAL", "INTEGER").Replace(c.sqlType)
+		statements = append(statements, fmt.Sprintf("%s ALTER COLUMN \"%s\" TYPE %s USING \"%s\"::%s;", alter, c.name, sqlType, c.name, sqlType))
+	}
+
+	nullable := existing.Nullable == "YES"
+	if nullable && !c.nullable {
+		statements = append(statements, fmt.Sprintf("%s ALTER COLUMN \"%s\" SET NOT NULL;", alter, c.name))
+	} else if !nullable && c.nullable {
+		statements = append(statements, fmt.Sprintf("%s ALTER COLUMN \"%s\" DROP NOT NULL;", alter, c.name))
+	}
+
+	if c.column.Default != "" && !existing.Default.Valid {
+		statements = append(statements, fmt.Sprintf("%s ALTER COLUMN \"%s\" SET DEFAULT %s;", alter, c.name, c.column.Default))
+	}
+
+	if c.column.Unique && !contains(table.unique, c.name) {
+		statements = append(statements, fmt.Sprintf("%s ADD CONSTRAINT \"%s\" UNIQUE (\"%s\");", alter, uniqueName(table.name, c.name), c.name))
+	}
+
+	return statements
+}
+
+// hasType returns true if the column has the given SQL type, ignoring the length of the type
+func (c *dbColumn) hasType(sqlType string) bool {
+	return c.UDTName == udtName(sqlType)
+}
+
+// udtNames maps SQL types and aliases to the names used in information_schema.columns.udt_name
+var udtNames = map[string]string{
+	"bigint":                      "int8",
+	"bigserial":                   "int8",
+	"serial8":                     "int8",
+	"integer":                     "int4",
+	"int":                         "int4",
+	"serial":                      "int4",
+	"serial4":                     "int4",
+	"smallint":                    "int2",
+	"smallserial":                 "int2",
+	"serial2":                     "int2",
+	"boolean":                     "bool",
+	"real":                        "float4",
+	"double precision":            "float8",
+	"timestamptz":                 "timestamptz",
+	"timestamp with time zone":    "timestamptz",
+	"timestamp without time zone": "timestamp",
+	"time with time zone":         "timetz",
+	"time without time zone":      "time",
+	"decimal":                     "numeric",
+	"character varying":           "varchar",
+	"character":                   "bpchar",
+	"char":                        "bpchar",
+}
+
+// udtName returns the udt_name of a SQL type, for example "_int8" for BIGINT[]
+func udtName(sqlType string) string {
+	name := strings.ToLower(strings.TrimSpace(sqlType))
+	array := strings.HasSuffix(name, "[]")
+	name = strings.TrimSuffix(name, "[]")
+	if i := strings.Index(name, "("); i >= 0 {
+		name = strings.TrimSpace(name[:i])
+	}
+	if x, ok := udtNames[name]; ok {
+		name = x
+	}
+	if array {
+		name = "_" + name
+	}
+
+	return name
+}
@@ -0,0 +1,189 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+type ddlModel struct {
+	PikaTableName string `pika:"ddl_model"`
+
+	ID        int64             `db:"id" pika:"pk,omitempty"`
+	Email     string            `db:"email" pika:"unique"`
+	Name      *string           `db:"name" pika:"index"`
+	Code      string            `db:"code" pika:"type=VARCHAR(10)"`
+	Age       sql.NullInt32     `db:"age"`
+	Score     float64           `db:"score"`
+	Active    bool              `db:"active" pika:"default=TRUE"`
+	Tags      pq.StringArray    `db:"tags"`
+	Labels    map[string]string `db:"labels"`
+	Raw       json.RawMessage   `db:"raw"`
+	CreatedAt time.Time         `db:"created_at" pika:"omitempty,default=NOW()"`
+	DeletedAt *time.Time        `db:"deleted_at"`
+}
+
+func TestDDL(t *testing.T) {
+	ddl, err := DDL[ddlModel]()
+	require.Nil(t, err)
+
+	expected := `CREATE TABLE "ddl_model" (
+	"id" BIGSERIAL NOT NULL,
+	"email" TEXT NOT NULL UNIQUE,
+	"name" TEXT,
+	"code" VARCHAR(10) NOT NULL,
+	"age" INTEGER,
+	"score" DOUBLE PRECISION NOT NULL,
+	"active" BOOLEAN NOT NULL DEFAULT TRUE,
+	"tags" TEXT[] NOT NULL,
+	"labels" JSONB NOT NULL,
+	"raw" JSONB NOT NULL,
+	"created_at" TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	"deleted_at" TIMESTAMPTZ,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX "ddl_model_name_idx" ON "ddl_model" ("name");`
+	require.Equal(t, expected, ddl)
+
+	info := Model[ddlModel]()
+	require.Equal(t, "VARCHAR(10)", info.Column("code").SQLType)
+	require.Equal(t, "NOW()", info.Column("created_at").Default)
+	require.True(t, info.Column("created_at").OmitEmpty)
+	require.Equal(t, "created_at", info.Column("created_at").PikaName)
+}
+
+func TestDDLCompositePrimaryKey(t *testing.T) {
+	ddl, err := DDL[membershipModel]()
+	require.Nil(t, err)
+
+	expected := `CREATE TABLE "membership_model" (
+	"org_id" BIGINT NOT NULL,
+	"user_id" BIGINT NOT NULL,
+	"role" TEXT NOT NULL,
+	PRIMARY KEY ("org_id", "user_id")
+);`
+	require.Equal(t, expected, ddl)
+}
+
+func TestDDLSkipsJoinedColumns(t *testing.T) {
+	ddl, err := DDL[joinSimpleModelMain]()
+	require.Nil(t, err)
+	require.NotContains(t, ddl, "join_model_foreign")
+}
+
+func TestDDLUnsupportedType(t *testing.T) {
+	type unsupportedModel struct {
+		Fn func() `db:"fn"`
+	}
+
+	_, err := DDL[unsupportedModel]()
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestGoSQLType(t *testing.T) {
+	tests := []struct {
+		value    any
+		sqlType  string
+		nullable bool
+	}{
+		{int32(0), "INTEGER", false},
+		{int16(0), "SMALLINT", false},
+		{uint64(0), "NUMERIC(20)", false},
+		{uint(0), "NUMERIC(20)", false},
+		{uint32(0), "BIGINT", false},
+		{float32(0), "REAL", false},
+		{[]byte{}, "BYTEA", false},
+		{[]int64{}, "BIGINT[]", false},
+		{[][]string{}, "JSONB", false},
+		{struct{ A int }{}, "JSONB", false},
+		{sql.NullString{}, "TEXT", true},
+		{sql.NullTime{}, "TIMESTAMPTZ", true},
+		{pq.Int64Array{}, "BIGINT[]", false},
+		{new(int), "BIGINT", true},
+	}
+
+	for _, test := range tests {
+		sqlType, nullable, err := goSQLType(reflect.TypeOf(test.value))
+		require.Nil(t, err)
+		require.Equal(t, test.sqlType, sqlType, "%T", test.value)
+		require.Equal(t, test.nullable, nullable, "%T", test.value)
+	}
+}
+
+func TestUDTName(t *testing.T) {
+	require.Equal(t, "int8", udtName("BIGSERIAL"))
+	require.Equal(t, "_text", udtName("TEXT[]"))
+	require.Equal(t, "varchar", udtName("VARCHAR(10)"))
+	require.Equal(t, "float8", udtName("DOUBLE PRECISION"))
+	require.Equal(t, "uuid", udtName("UUID"))
+	require.Equal(t, "numeric", udtName("NUMERIC(20)"))
+}
+
+func TestIndexName(t *testing.T) {
+	require.Equal(t, "ddl_model_name_idx", indexName("public.ddl_model", "name"))
+	require.Equal(t, "ddl_model_email_key", uniqueName("ddl_model", "email"))
+
+	// Names longer than PostgreSQL identifiers are truncated with a hash
+	table := "a_table_with_a_rather_long_name_for_testing"
+	name := indexName(table, "and_a_long_column_name")
+	require.Len(t, name, maxIdentifierLength)
+	require.Equal(t, "a_table_with_a_rather_long_name_for_testing_and_a_long_", name[:55])
+	require.NotEqual(t, name, indexName(table, "and_a_long_column_name2"))
+	require.Equal(t, name, indexName(table, "and_a_long_column_name"))
+}
+
+func TestDiff(t *testing.T) {
+	psql := newPsql(t)
+	psql.db.MustExec("DROP TABLE IF EXISTS ddl_model")
+	ctx := context.Background()
+
+	// A missing table is created
+	statements, err := psql.Diff(ctx, ddlModel{})
+	require.Nil(t, err)
+	ddl, err := DDL[ddlModel]()
+	require.Nil(t, err)
+	require.Equal(t, []string{ddl}, statements)
+
+	psql.db.MustExec(ddl)
+	statements, err = psql.Diff(ctx, &ddlModel{})
+	require.Nil(t, err)
+	require.Empty(t, statements)
+
+	// Drift between the model and the table
+	psql.db.MustExec(`DROP INDEX ddl_model_name_idx`)
+	psql.db.MustExec(`ALTER TABLE ddl_model DROP COLUMN raw`)
+	psql.db.MustExec(`ALTER TABLE ddl_model ALTER COLUMN score TYPE INTEGER`)
+	psql.db.MustExec(`ALTER TABLE ddl_model ALTER COLUMN email DROP NOT NULL`)
+	psql.db.MustExec(`ALTER TABLE ddl_model ALTER COLUMN deleted_at SET NOT NULL`)
+	psql.db.MustExec(`ALTER TABLE ddl_model ALTER COLUMN active DROP DEFAULT`)
+	psql.db.MustExec(`ALTER TABLE ddl_model ADD COLUMN extra TEXT`)
+	psql.db.MustExec(`ALTER TABLE ddl_model DROP CONSTRAINT ddl_model_email_key`)
+
+	statements, err = psql.Diff(ctx, ddlModel{})
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		`ALTER TABLE "ddl_model" ALTER COLUMN "email" SET NOT NULL;`,
+		`ALTER TABLE "ddl_model" ADD CONSTRAINT "ddl_model_email_key" UNIQUE ("email");`,
+		`CREATE INDEX "ddl_model_name_idx" ON "ddl_model" ("name");`,
+		`ALTER TABLE "ddl_model" ALTER COLUMN "score" TYPE DOUBLE PRECISION USING "score"::DOUBLE PRECISION;`,
+		`ALTER TABLE "ddl_model" ALTER COLUMN "active" SET DEFAULT TRUE;`,
+		`ALTER TABLE "ddl_model" ADD COLUMN "raw" JSONB NOT NULL;`,
+		`ALTER TABLE "ddl_model" ALTER COLUMN "deleted_at" DROP NOT NULL;`,
+	}, statements)
+
+	for _, statement := range statements {
+		psql.db.MustExec(statement)
+	}
+	statements, err = psql.Diff(ctx, ddlModel{})
+	require.Nil(t, err)
+	require.Empty(t, statements)
+}
@@ -0,0 +1,241 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/antlr/antlr4/runtime/Go/antlr/v4"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"go.ciq.dev/pika/parser"
+)
+
+// Static errors for err113 compliance
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrMultipleRows        = errors.New("multiple rows found")
+	ErrUniqueViolation     = errors.New("unique violation")
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+	ErrCheckViolation      = errors.New("check violation")
+	ErrSerialization       = errors.New("serialization failure")
+)
+
+// PostgreSQL error codes mapped to typed errors
+const (
+	pqUniqueViolation      = "23505"
+	pqForeignKeyViolation  = "23503"
+	pqCheckViolation       = "23514"
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+)
+
+// pqKeyColumns matches the columns in the detail of key violations,
+// for example Key (org_id, name)=(1, test) already exists.
+var pqKeyColumns = regexp.MustCompile(`^Key \((.+?)\)=`)
+
+// ConstraintError is returned when a query violates a constraint.
+// It matches ErrUniqueViolation, ErrForeignKeyViolation or ErrCheckViolation with errors.Is,
+// and wraps the *pq.Error reported by PostgreSQL.
+// Example:
+//
+//	var constraintErr *ConstraintError
+//	if errors.As(err, &constraintErr) && errors.Is(err, ErrUniqueViolation) {
+//		log.Println(constraintErr.Constraint, constraintErr.Columns)
+//	}
+type ConstraintError struct {
+	// Kind is ErrUniqueViolation, ErrForeignKeyViolation or ErrCheckViolation
+	Kind error
+	// Table is the table of the constraint
+	Table string
+	// Constraint is the name of the violated constraint
+	Constraint string
+	// Columns are the columns of the violated key.
+	// Empty for check violations, as PostgreSQL doesn't report them.
+	Columns []string
+	// Err is the original error
+	Err *pq.Error
+}
+
+func (e *ConstraintError) Error() string {
+	return e.Err.Error()
+}
+
+// Is returns true for the kind of the violation
+func (e *ConstraintError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// FilterSyntaxError is returned for an AIP-160 filter that can't be applied.
+// It matches ErrInvalidFilter with errors.Is, and wraps the cause, for example ErrIdentifierNotAllowed.
+// Error returns the message of the cause, Annotated points at the offending token.
+type FilterSyntaxError struct {
+	// Filter is the filter that failed
+	Filter string
+	// Position is the offset of the first character of the token in the filter
+	Position int
+	// Line is the line of the token, starting at 1
+	Line int
+	// Column is the column of the token in the line, starting at 1
+	Column int
+	// Token is the text of the token, empty at the end of the filter
+	Token string
+	// Expected are the tokens that are accepted instead, if known
+	Expected []string
+	// Suggestions are acceptable identifiers close to an identifier that is not allowed
+	Suggestions []string
+	// Err is the original error
+	Err error
+}
+
+func (e *FilterSyntaxError) Error() string {
+	return e.Err.Error()
+}
+
+// Is returns true for ErrInvalidFilter
+func (e *FilterSyntaxError) Is(target error) bool {
+	return target == ErrInvalidFilter
+}
+
+func (e *FilterSyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Annotated returns a message for clients, with the line of the filter and a caret under the token.
+// Example:
+//
+//	line 1, column 20: identifier is not allowed: descrption
+//	title = "Test" AND descrption = "Test"
+//	                   ^^^^^^^^^^
+//	did you mean description?
+func (e *FilterSyntaxError) Annotated() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "line %d, column %d: %s", e.Line, e.Column, e.Error())
+
+	lines := strings.Split(e.Filter, "\n")
+	if e.Line > 0 && e.Line <= len(lines) {
+		line := []rune(strings.TrimRight(lines[e.Line-1], "\r"))
+		sb.WriteString("\n")
+		sb.WriteString(string(line))
+		sb.WriteString("\n")
+
+		// Keep tabs, so the caret lines up with the token
+		column := min(e.Column-1, len(line))
+		for _, r := range line[:column] {
+			if r == '\t' {
+				sb.WriteRune(r)
+			} else {
+				sb.WriteRune(' ')
+			}
+		}
+
+		width := min(utf8.RuneCountInString(e.Token), len(line)-column)
+		sb.WriteString(strings.Repeat("^", max(width, 1)))
+	}
+
+	switch len(e.Expected) {
+	case 0:
+	case 1:
+		sb.WriteString("\nexpected ")
+		sb.WriteString(e.Expected[0])
+	default:
+		sb.WriteString("\nexpected one of ")
+		sb.WriteString(strings.Join(e.Expected, ", "))
+	}
+	if len(e.Suggestions) > 0 {
+		sb.WriteString("\ndid you mean ")
+		sb.WriteString(strings.Join(e.Suggestions, " or "))
+		sb.WriteString("?")
+	}
+
+	return sb.String()
+}
+
+// newFilterSyntaxError returns err at token t, accepting the expected token types instead
+func newFilterSyntaxError(lexer *parser.FilterLexer, t antlr.Token, err error, expected ...int) *FilterSyntaxError {
+	syntaxErr := &FilterSyntaxError{
+		Position: t.GetStart(),
+		Line:     t.GetLine(),
+		Column:   t.GetColumn() + 1,
+		Err:      err,
+	}
+	if t.GetTokenType() != antlr.TokenEOF {
+		syntaxErr.Token = t.GetText()
+	}
+
+	for _, tokenType := range expected {
+		syntaxErr.Expected = append(syntaxErr.Expected, filterTokenName(lexer, tokenType))
+	}
+
+	return syntaxErr
+}
+
+// filterTokenName returns the literal of a token type, such as '=', or its symbolic name, such as STRING
+func filterTokenName(lexer *parser.FilterLexer, tokenType int) string {
+	if tokenType < len(lexer.LiteralNames) && lexer.LiteralNames[tokenType] != "" {
+		return lexer.LiteralNames[tokenType]
+	}
+	if tokenType < len(lexer.SymbolicNames) {
+		return lexer.SymbolicNames[tokenType]
+	}
+
+	return strconv.Itoa(tokenType)
+}
+
+// queryError maps errors of queries to typed errors, wrapping the original error.
+// sql.ErrNoRows matches ErrNotFound, and constraint violations are returned as ConstraintError.
+// Serialization failures and deadlocks match ErrSerialization, as the transaction can be retried.
+func queryError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case pqUniqueViolation:
+		return newConstraintError(ErrUniqueViolation, pqErr)
+	case pqForeignKeyViolation:
+		return newConstraintError(ErrForeignKeyViolation, pqErr)
+	case pqCheckViolation:
+		return newConstraintError(ErrCheckViolation, pqErr)
+	case pqSerializationFailure, pqDeadlockDetected:
+		return fmt.Errorf("%w: %w", ErrSerialization, err)
+	}
+
+	return err
+}
+
+func newConstraintError(kind error, pqErr *pq.Error) *ConstraintError {
+	constraintErr := &ConstraintError{
+		Kind:       kind,
+		Table:      pqErr.Table,
+		Constraint: pqErr.Constraint,
+		Err:        pqErr,
+	}
+
+	if match := pqKeyColumns.FindStringSubmatch(pqErr.Detail); match != nil {
+		for _, column := range strings.Split(match[1], ",") {
+			constraintErr.Columns = append(constraintErr.Columns, strings.Trim(strings.TrimSpace(column), `"`))
+		}
+	}
+
+	return constraintErr
+}
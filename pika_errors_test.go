@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.ciq.dev/pika/parser"
+)
+
+func TestQueryErrorNotFound(t *testing.T) {
+	err := queryError(fmt.Errorf("get: %w", sql.ErrNoRows))
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.Nil(t, queryError(nil))
+
+	other := errors.New("other")
+	require.Equal(t, other, queryError(other))
+}
+
+func TestQueryErrorUniqueViolation(t *testing.T) {
+	pqErr := &pq.Error{
+		Code:       "23505",
+		Message:    `duplicate key value violates unique constraint "articles_org_id_name_key"`,
+		Detail:     `Key (org_id, "Name")=(1, test) already exists.`,
+		Table:      "articles",
+		Constraint: "articles_org_id_name_key",
+	}
+	err := queryError(pqErr)
+	require.ErrorIs(t, err, ErrUniqueViolation)
+	require.NotErrorIs(t, err, ErrForeignKeyViolation)
+	require.EqualError(t, err, pqErr.Error())
+
+	var constraintErr *ConstraintError
+	require.ErrorAs(t, err, &constraintErr)
+	require.Equal(t, "articles", constraintErr.Table)
+	require.Equal(t, "articles_org_id_name_key", constraintErr.Constraint)
+	require.Equal(t, []string{"org_id", "Name"}, constraintErr.Columns)
+
+	var unwrapped *pq.Error
+	require.ErrorAs(t, err, &unwrapped)
+	require.Equal(t, pqErr, unwrapped)
+}
+
+func TestQueryErrorForeignKeyViolation(t *testing.T) {
+	err := queryError(&pq.Error{
+		Code:       "23503",
+		Detail:     `Key (author_id)=(5) is not present in table "authors".`,
+		Constraint: "articles_author_id_fkey",
+	})
+	require.ErrorIs(t, err, ErrForeignKeyViolation)
+
+	var constraintErr *ConstraintError
+	require.ErrorAs(t, err, &constraintErr)
+	require.Equal(t, []string{"author_id"}, constraintErr.Columns)
+}
+
+func TestQueryErrorCheckViolation(t *testing.T) {
+	err := queryError(&pq.Error{Code: "23514", Constraint: "articles_title_check"})
+	require.ErrorIs(t, err, ErrCheckViolation)
+
+	var constraintErr *ConstraintError
+	require.ErrorAs(t, err, &constraintErr)
+	require.Equal(t, "articles_title_check", constraintErr.Constraint)
+	require.Nil(t, constraintErr.Columns)
+}
+
+func TestQueryErrorSerialization(t *testing.T) {
+	for _, code := range []pq.ErrorCode{"40001", "40P01"} {
+		pqErr := &pq.Error{Code: code}
+		err := queryError(pqErr)
+		require.ErrorIs(t, err, ErrSerialization)
+
+		var unwrapped *pq.Error
+		require.ErrorAs(t, err, &unwrapped)
+		require.Equal(t, pqErr, unwrapped)
+	}
+
+	pqErr := &pq.Error{Code: "42P01"}
+	require.Equal(t, pqErr, queryError(pqErr))
+}
+
+func TestCreateUniqueViolation(t *testing.T) {
+	qs := newPsqlQuery[simpleModel1](t)
+	createTestEntries(t, qs.(*basePsql[simpleModel1]).psql)
+
+	err := qs.Create(context.Background(), &simpleModel1{ID: 1, Title: "Test", Description: "Test"})
+	require.ErrorIs(t, err, ErrUniqueViolation)
+
+	var constraintErr *ConstraintError
+	require.ErrorAs(t, err, &constraintErr)
+	require.Equal(t, "simple_model_1", constraintErr.Table)
+	require.Equal(t, "simple_model_1_pkey", constraintErr.Constraint)
+	require.Equal(t, []string{"id"}, constraintErr.Columns)
+}
+
+func TestAIP160FilterSyntaxError(t *testing.T) {
+	qs := newPsqlQuery[simpleModel1](t)
+	options := AIPFilterOptions{
+		AcceptableIdentifiers: []string{"title", "description"},
+	}
+
+	_, err := qs.AIP160(`title = "Test" AND descrption = "Test"`, options)
+	require.ErrorIs(t, err, ErrInvalidFilter)
+	require.ErrorIs(t, err, ErrIdentifierNotAllowed)
+	require.EqualError(t, err, "identifier is not allowed: descrption")
+
+	var syntaxErr *FilterSyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+	require.Equal(t, 19, syntaxErr.Position)
+	require.Equal(t, 1, syntaxErr.Line)
+	require.Equal(t, 20, syntaxErr.Column)
+	require.Equal(t, "descrption", syntaxErr.Token)
+	require.Equal(t, []string{"description"}, syntaxErr.Suggestions)
+	require.Equal(t, `line 1, column 20: identifier is not allowed: descrption
+title = "Test" AND descrption = "Test"
+                   ^^^^^^^^^^
+did you mean description?`, syntaxErr.Annotated())
+}
+
+func TestAIP160FilterSyntaxErrorMultiline(t *testing.T) {
+	qs := newPsqlQuery[simpleModel1](t)
+
+	_, err := qs.AIP160("title = \"Test\"\n\tAND title foo", AIPFilterOptions{})
+	require.ErrorIs(t, err, ErrUnexpectedIdentifier)
+
+	var syntaxErr *FilterSyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+	require.Equal(t, 26, syntaxErr.Position)
+	require.Equal(t, 2, syntaxErr.Line)
+	require.Equal(t, 12, syntaxErr.Column)
+	require.Equal(t, []string{"'='", "'!='", "'<'", "'<='", "'>='", "'>'", "':'"}, syntaxErr.Expected)
+	require.Equal(t, "line 2, column 12: unexpected identifier: foo\n\tAND title foo\n\t          ^^^\nexpected one of '=', '!=', '<', '<=', '>=', '>', ':'", syntaxErr.Annotated())
+}
+
+func TestAIP160FilterSyntaxErrorLexer(t *testing.T) {
+	qs := newPsqlQuery[simpleModel1](t)
+
+	_, err := qs.AIP160(`title = "Test" AND # description = "Test"`, AIPFilterOptions{})
+	require.ErrorIs(t, err, ErrInvalidFilter)
+	require.ErrorIs(t, err, ErrUnexpectedToken)
+
+	var syntaxErr *FilterSyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+	require.Equal(t, 19, syntaxErr.Position)
+	require.Equal(t, 20, syntaxErr.Column)
+	require.Equal(t, "#", syntaxErr.Token)
+
+	_, err = qs.AIP160(`title = "Test`, AIPFilterOptions{})
+	require.ErrorAs(t, err, &syntaxErr)
+	require.Equal(t, 8, syntaxErr.Position)
+	require.Equal(t, `"Test`, syntaxErr.Token)
+}
+
+func TestAIP160FilterSyntaxErrorExpected(t *testing.T) {
+	qs := newPsqlQuery[simpleModel1](t)
+	options := AIPFilterOptions{
+		Identifiers: map[string]AIPFilterIdentifier{
+			"id": {AcceptedTypes: []int{parser.FilterLexerNUM_INT}},
+		},
+	}
+
+	_, err := qs.AIP160(`id =`, options)
+	require.ErrorIs(t, err, ErrUnexpectedEndOfFilter)
+
+	var syntaxErr *FilterSyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+	require.Equal(t, 4, syntaxErr.Position)
+	require.Equal(t, "", syntaxErr.Token)
+	require.Equal(t, []string{"NUM_INT"}, syntaxErr.Expected)
+
+	_, err = qs.AIP160(`id = 1 AND`, options)
+	require.ErrorAs(t, err, &syntaxErr)
+	require.Equal(t, []string{"IDENTIFIER"}, syntaxErr.Expected)
+	require.Equal(t, "line 1, column 11: unexpected end of filter\nid = 1 AND\n          ^\nexpected IDENTIFIER", syntaxErr.Annotated())
+
+	_, err = qs.AIP160(`id = "1"`, options)
+	require.ErrorIs(t, err, ErrTypeNotAccepted)
+	require.ErrorAs(t, err, &syntaxErr)
+	require.Equal(t, `"1"`, syntaxErr.Token)
+	require.Equal(t, []string{"NUM_INT"}, syntaxErr.Expected)
+}
+
+func TestSuggestIdentifiers(t *testing.T) {
+	options := AIPFilterOptions{
+		AcceptableIdentifiers: []string{"title", "description", "create_time", "update_time", "tile"},
+	}
+
+	require.Equal(t, []string{"title", "tile"}, options.suggestIdentifiers("titel"))
+	require.Equal(t, []string{"create_time"}, options.suggestIdentifiers("create_tme"))
+	require.Equal(t, []string{"description"}, options.suggestIdentifiers("Description"))
+	require.Nil(t, options.suggestIdentifiers("author"))
+}
@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.ciq.dev/pika/pikapb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Static errors for err113 compliance
+var (
+	ErrInvalidFieldMask = errors.New("invalid field mask")
+)
+
+// UpdateMask updates the columns of the fields in an AIP-134 update mask
+func (b *basePsql[T]) UpdateMask(ctx context.Context, x *T, mask *fieldmaskpb.FieldMask, msg proto.Message, opts ProtoReflectOptions) error {
+	if b.err != nil {
+		return b.err
+	}
+
+	columns, err := updateMaskColumns(b.model, msg, mask, opts)
+	if err != nil {
+		return err
+	}
+
+	return b.UpdateFields(ctx, x, columns...)
+}
+
+// WithReadMask selects the columns of the fields in an AIP-157 read mask
+func (b *basePsql[T]) WithReadMask(mask *fieldmaskpb.FieldMask, msg proto.Message, opts ProtoReflectOptions) QuerySet[T] {
+	if b.err != nil {
+		return b
+	}
+
+	err := checkProtoTable(msg.ProtoReflect().Descriptor(), b.model)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	paths := mask.GetPaths()
+	if len(paths) == 0 || contains(paths, "*") {
+		return b
+	}
+
+	var includes []string
+	include := func(column *ColumnInfo) {
+		if column != nil && !contains(includes, column.PikaName) {
+			includes = append(includes, column.PikaName)
+		}
+	}
+	for _, path := range paths {
+		columns, err := maskColumns(b.model, msg.ProtoReflect().Descriptor(), path, opts)
+		if err != nil {
+			b.err = err
+			return b
+		}
+		for _, column := range columns {
+			include(column)
+		}
+	}
+
+	// Pagination needs the primary key and the order, which may be set after the mask
+	b.includeOrderBy = true
+
+	return b.Include(includes...)
+}
+
+// orderByColumns returns includes with the primary key and the order by columns added
+func (b *basePsql[T]) orderByColumns(includes []string) []string {
+	includes = slices.Clone(includes)
+	include := func(column *ColumnInfo) {
+		if column != nil && !contains(includes, column.PikaName) {
+			includes = append(includes, column.PikaName)
+		}
+	}
+
+	for _, name := range b.model.PrimaryKey {
+		include(b.model.Column(name))
+	}
+	orderBy := b.orderBy
+	if defaultOrderBy := b.model.DefaultOrderBy; defaultOrderBy != "" {
+		orderBy = append([]string{defaultOrderBy}, orderBy...)
+	}
+	for _, o := range orderBy {
+		include(b.model.Column(strings.TrimPrefix(o, "-")))
+	}
+
+	return includes
+}
+
+// readMaskColumn returns the column of a read mask path, mapped the same way as in ProtoReflect.
+// Nested paths are flattened, for example spec.image is the spec_image column,
+// and fields of messages stored in a JSONB column select the whole column.
+// Returns true if the path is a flattened message, so the columns starting with the column are selected.
+// Returns ErrInvalidFieldMask if a path is not a field of desc or is excluded.
+func readMaskColumn(desc protoreflect.MessageDescriptor, path string, opts ProtoReflectOptions) (string, bool, error) {
+	column := ""
+	prefix := ""
+	json := false
+	var fd protoreflect.FieldDescriptor
+	for _, name := range strings.Split(path, ".") {
+		if fd != nil {
+			// Only singular messages have nested fields
+			if !protoNestedMessage(fd) || fd.IsList() || fd.IsMap() {
+				return "", false, fmt.Errorf("%w: %s is not a message", ErrInvalidFieldMask, strings.TrimSuffix(prefix, "."))
+			}
+			desc = fd.Message()
+		}
+
+		fd = desc.Fields().ByName(protoreflect.Name(name))
+		prefix += name
+		if fd == nil || contains(opts.Exclude, prefix) {
+			return "", false, fmt.Errorf("%w: unknown field %s", ErrInvalidFieldMask, path)
+		}
+		prefix += "."
+
+		if json {
+			continue
+		}
+		if column != "" {
+			column += "_"
+		}
+		column += protoColumnName(fd, opts)
+
+		fieldOpts, _ := proto.GetExtension(fd.Options(), pikapb.E_Field).(*pikapb.FieldOptions)
+		json = fieldOpts.GetJsonb()
+	}
+
+	return column, !json && protoNestedMessage(fd) && !fd.IsList() && !fd.IsMap(), nil
+}
+
+// maskColumns returns the columns of a field mask path, see readMaskColumn.
+// Flattened messages return all of their columns, except those of excluded fields.
+func maskColumns(info *ModelInfo, desc protoreflect.MessageDescriptor, path string, opts ProtoReflectOptions) ([]*ColumnInfo, error) {
+	name, nested, err := readMaskColumn(desc, path, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []*ColumnInfo
+	if column := info.Column(name); column != nil {
+		columns = append(columns, column)
+	}
+	if !nested {
+		return columns, nil
+	}
+
+	// Columns of excluded fields, resolved without the exclusions
+	var excluded []string
+	var excludedPrefixes []string
+	for _, excludedPath := range opts.Exclude {
+		column, nested, err := readMaskColumn(desc, excludedPath, ProtoReflectOptions{ColumnName: opts.ColumnName})
+		if err != nil {
+			continue
+		}
+		excluded = append(excluded, column)
+		if nested {
+			excludedPrefixes = append(excludedPrefixes, column+"_")
+		}
+	}
+
+	for _, column := range info.Columns {
+		if !strings.HasPrefix(column.Name, name+"_") || contains(excluded, column.Name) {
+			continue
+		}
+		if slices.ContainsFunc(excludedPrefixes, func(prefix string) bool { return strings.HasPrefix(column.Name, prefix) }) {
+			continue
+		}
+		columns = append(columns, column)
+	}
+
+	return columns, nil
+}
+
+// updateMaskColumns returns the columns of the fields in an update mask.
+// Paths are mapped to columns of the model with maskColumns, so fields of JSONB messages
+// update the whole column, and flattened messages update all of their columns.
+// Without paths, the fields set in msg are used. With "*", all fields are used.
+// Excluded fields, fields without a column and primary key columns are skipped if not named explicitly.
+func updateMaskColumns(info *ModelInfo, msg proto.Message, mask *fieldmaskpb.FieldMask, opts ProtoReflectOptions) ([]string, error) {
+	m := msg.ProtoReflect()
+	desc := m.Descriptor()
+	paths := mask.GetPaths()
+
+	err := checkProtoTable(desc, info)
+	if err != nil {
+		return nil, err
+	}
+
+	explicit := len(paths) > 0 && !contains(paths, "*")
+	if !explicit {
+		var fieldPaths []string
+		fields := desc.Fields()
+		for i := range fields.Len() {
+			fd := fields.Get(i)
+			if contains(opts.Exclude, string(fd.Name())) || (len(paths) == 0 && !m.Has(fd)) {
+				continue
+			}
+			fieldPaths = append(fieldPaths, string(fd.Name()))
+		}
+		paths = fieldPaths
+	}
+
+	var columns []string
+	for _, path := range paths {
+		pathColumns, err := maskColumns(info, desc, path, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		found := false
+		for _, column := range pathColumns {
+			// Columns selected from other tables can't be updated
+			if strings.Contains(column.PikaName, ".") {
+				continue
+			}
+			found = true
+
+			switch {
+			case column.PrimaryKey && explicit:
+				return nil, fmt.Errorf("%w: field %s: %w", ErrInvalidFieldMask, path, ErrPrimaryKeyUpdate)
+			case column.PrimaryKey, contains(columns, column.Name):
+				continue
+			}
+			columns = append(columns, column.Name)
+		}
+		if !found && explicit {
+			return nil, fmt.Errorf("%w: field %s has no column", ErrInvalidFieldMask, path)
+		}
+	}
+
+	return columns, nil
+}
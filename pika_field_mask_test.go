@@ -0,0 +1,228 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	pikatestpb "go.ciq.dev/pika/testproto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestUpdateMaskColumns(t *testing.T) {
+	info := Model[simpleModel1]()
+	msg := &pikatestpb.SimpleModel1{Id: 1, Title: "Hello"}
+	mask := func(paths ...string) *fieldmaskpb.FieldMask {
+		return &fieldmaskpb.FieldMask{Paths: paths}
+	}
+
+	columns, err := updateMaskColumns(info, msg, mask("description", "title"), ProtoReflectOptions{})
+	require.Nil(t, err)
+	require.Equal(t, []string{"description", "title"}, columns)
+
+	// All fields except the primary key
+	columns, err = updateMaskColumns(info, msg, mask("*"), ProtoReflectOptions{})
+	require.Nil(t, err)
+	require.Equal(t, []string{"title", "description"}, columns)
+
+	columns, err = updateMaskColumns(info, msg, mask("*"), ProtoReflectOptions{Exclude: []string{"description"}})
+	require.Nil(t, err)
+	require.Equal(t, []string{"title"}, columns)
+
+	// Without a mask, the fields set in the message
+	columns, err = updateMaskColumns(info, msg, nil, ProtoReflectOptions{})
+	require.Nil(t, err)
+	require.Equal(t, []string{"title"}, columns)
+
+	_, err = updateMaskColumns(info, msg, mask("name"), ProtoReflectOptions{})
+	require.ErrorIs(t, err, ErrInvalidFieldMask)
+	require.Equal(t, "invalid field mask: unknown field name", err.Error())
+
+	_, err = updateMaskColumns(info, msg, mask("title"), ProtoReflectOptions{Exclude: []string{"title"}})
+	require.ErrorIs(t, err, ErrInvalidFieldMask)
+
+	_, err = updateMaskColumns(info, msg, mask("id"), ProtoReflectOptions{})
+	require.ErrorIs(t, err, ErrInvalidFieldMask)
+	require.ErrorIs(t, err, ErrPrimaryKeyUpdate)
+
+	// Fields are mapped to columns the same way as in ProtoReflect, including the column option
+	columns, err = updateMaskColumns(Model[annotated4Model](), &pikatestpb.Annotated4{}, mask("create_time"), ProtoReflectOptions{})
+	require.Nil(t, err)
+	require.Equal(t, []string{"created_at"}, columns)
+
+	_, err = updateMaskColumns(info, &pikatestpb.Annotated4{}, mask("create_time"), ProtoReflectOptions{})
+	require.ErrorIs(t, err, ErrInvalidFieldMask)
+	require.Equal(t, "invalid field mask: field create_time has no column", err.Error())
+
+	// Nested fields are flattened, and messages update all of their columns
+	nested := Model[nested5Model]()
+	nestedMsg := &pikatestpb.Nested5{}
+	columns, err = updateMaskColumns(nested, nestedMsg, mask("spec.container.image", "name"), ProtoReflectOptions{})
+	require.Nil(t, err)
+	require.Equal(t, []string{"spec_container_image", "name"}, columns)
+
+	columns, err = updateMaskColumns(nested, nestedMsg, mask("spec"), ProtoReflectOptions{})
+	require.Nil(t, err)
+	require.Equal(t, []string{"spec_container_image"}, columns)
+
+	_, err = updateMaskColumns(nested, nestedMsg, mask("spec"), ProtoReflectOptions{Exclude: []string{"spec.container"}})
+	require.ErrorIs(t, err, ErrInvalidFieldMask)
+
+	_, err = updateMaskColumns(nested, nestedMsg, mask("spec.container.image"), ProtoReflectOptions{Exclude: []string{"spec"}})
+	require.ErrorIs(t, err, ErrInvalidFieldMask)
+
+	_, err = updateMaskColumns(nested, nestedMsg, mask("spec.image"), ProtoReflectOptions{})
+	require.ErrorIs(t, err, ErrInvalidFieldMask)
+
+	// Fields of JSONB messages update the whole column
+	columns, err = updateMaskColumns(nested, nestedMsg, mask("metadata.deleted", "metadata.status"), ProtoReflectOptions{})
+	require.Nil(t, err)
+	require.Equal(t, []string{"metadata"}, columns)
+
+	// Without a mask, set messages update all of their columns
+	nestedMsg.Spec = &pikatestpb.Spec5{Replicas: 2}
+	columns, err = updateMaskColumns(nested, nestedMsg, nil, ProtoReflectOptions{})
+	require.Nil(t, err)
+	require.Equal(t, []string{"spec_container_image"}, columns)
+}
+
+func TestUpdateMask(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	hook := &recordingHook{}
+	psql.AddQueryHook(hook)
+	ctx := context.Background()
+
+	msg := &pikatestpb.SimpleModel1{Id: 2, Title: "Changed"}
+	x, err := FromProto[simpleModel1](msg)
+	require.Nil(t, err)
+
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"title"}}
+	err = Q[simpleModel1](psql).UpdateMask(ctx, x, mask, msg, ProtoReflectOptions{})
+	require.Nil(t, err)
+
+	expectedQuery := `UPDATE "simple_model_1" SET "title" = $2 WHERE ("id" = $1) RETURNING "id", "title", "description"`
+	require.Equal(t, expectedQuery, hook.after[0].Query)
+	require.Equal(t, []any{2, "Changed"}, hook.after[0].Args)
+
+	ret, err := Q[simpleModel1](psql).F("id", 2).Get(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "Changed", ret.Title)
+	require.Equal(t, "Test2", ret.Description)
+
+	mask.Paths = []string{"id"}
+	err = Q[simpleModel1](psql).UpdateMask(ctx, x, mask, msg, ProtoReflectOptions{})
+	require.ErrorIs(t, err, ErrInvalidFieldMask)
+	require.Len(t, hook.after, 2)
+}
+
+func TestWithReadMask(t *testing.T) {
+	psql := newPsql(t)
+	msg := &pikatestpb.SimpleModel1{}
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"title"}}
+
+	// The primary key is always included
+	qs := Q[simpleModel1](psql).WithReadMask(mask, msg, ProtoReflectOptions{})
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title" FROM "simple_model_1" "simpleModel1"`
+	actualQuery, _ := qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	// And the order by columns
+	qs = Q[simpleModel1](psql).OrderBy("-description").WithReadMask(mask, msg, ProtoReflectOptions{})
+	expectedQuery = `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" ORDER BY "simpleModel1"."description" DESC`
+	actualQuery, _ = qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	mask.Paths = []string{"*"}
+	qs = Q[simpleModel1](psql).WithReadMask(mask, msg, ProtoReflectOptions{})
+	expectedQuery = `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1"`
+	actualQuery, _ = qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	// Fields are mapped to columns the same way as in ProtoReflect
+	mask.Paths = []string{"create_time", "internal"}
+	annotated := Q[annotated4Model](psql).WithReadMask(mask, &pikatestpb.Annotated4{}, ProtoReflectOptions{})
+	expectedQuery = `SELECT "annotated4Model"."id", "annotated4Model"."created_at", "annotated4Model"."internal" FROM "simple_model_1" "annotated4Model"`
+	actualQuery, _ = annotated.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	mask.Paths = []string{"body"}
+	_, err := Q[simpleModel1](psql).WithReadMask(mask, msg, ProtoReflectOptions{}).All(context.Background())
+	require.ErrorIs(t, err, ErrInvalidFieldMask)
+}
+
+func TestWithReadMaskOrderBy(t *testing.T) {
+	psql := newPsql(t)
+	msg := &pikatestpb.SimpleModel1{}
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"title"}}
+
+	// Order by columns set after the mask are selected too
+	qs := Q[simpleModel1](psql).WithReadMask(mask, msg, ProtoReflectOptions{}).OrderBy("-description")
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" ORDER BY "simpleModel1"."description" DESC`
+	actualQuery, _ := qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+}
+
+func TestWithReadMaskGetPage(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	hook := &recordingHook{}
+	psql.AddQueryHook(hook)
+	ctx := context.Background()
+
+	mask := &fieldmaskpb.FieldMask{Paths: []string{"title"}}
+	req := &pikatestpb.TestRequest1{
+		PageSize: 2,
+		OrderBy:  "description desc",
+	}
+	page, nt, err := Q[simpleModel1](psql).WithReadMask(mask, &pikatestpb.SimpleModel1{}, ProtoReflectOptions{}).GetPage(ctx, req, ProtoReflect(&pikatestpb.SimpleModel1{}))
+	require.Nil(t, err)
+	require.Len(t, page, 2)
+	require.NotEmpty(t, nt)
+
+	// The order by column of the request is selected, so the next page can be read from the last row
+	require.Contains(t, hook.after[0].Query, `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM`)
+	require.Equal(t, "Test3", page[0].Description)
+
+	req.PageToken = nt
+	page, _, err = Q[simpleModel1](psql).WithReadMask(mask, &pikatestpb.SimpleModel1{}, ProtoReflectOptions{}).GetPage(ctx, req, ProtoReflect(&pikatestpb.SimpleModel1{}))
+	require.Nil(t, err)
+	require.Len(t, page, 1)
+	require.Equal(t, "Test", page[0].Description)
+}
+
+func TestWithReadMaskNested(t *testing.T) {
+	psql := newPsql(t)
+	msg := &pikatestpb.Nested5{}
+	mask := func(paths ...string) *fieldmaskpb.FieldMask {
+		return &fieldmaskpb.FieldMask{Paths: paths}
+	}
+
+	// Nested fields are flattened
+	qs := Q[nested5Model](psql).WithReadMask(mask("spec.container.image"), msg, ProtoReflectOptions{})
+	expectedQuery := `SELECT "nested5Model"."id", "nested5Model"."spec_container_image" FROM "nested_5" "nested5Model"`
+	actualQuery, _ := qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	// Messages select all of their columns
+	qs = Q[nested5Model](psql).WithReadMask(mask("spec"), msg, ProtoReflectOptions{})
+	actualQuery, _ = qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	// Fields of JSONB messages select the whole column
+	qs = Q[nested5Model](psql).WithReadMask(mask("metadata.deleted", "name"), msg, ProtoReflectOptions{})
+	expectedQuery = `SELECT "nested5Model"."id", "nested5Model"."name", "nested5Model"."metadata" FROM "nested_5" "nested5Model"`
+	actualQuery, _ = qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	for _, path := range []string{"spec.image", "sidecars.image", "name.first"} {
+		_, err := Q[nested5Model](psql).WithReadMask(mask(path), msg, ProtoReflectOptions{}).All(context.Background())
+		require.ErrorIs(t, err, ErrInvalidFieldMask, path)
+	}
+
+	_, err := Q[nested5Model](psql).WithReadMask(mask("spec.container.image"), msg, ProtoReflectOptions{Exclude: []string{"spec.container"}}).All(context.Background())
+	require.ErrorIs(t, err, ErrInvalidFieldMask)
+}
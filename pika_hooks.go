@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Operation names used in QueryEvent
+const (
+	OperationCreate       = "Create"
+	OperationUpdate       = "Update"
+	OperationDelete       = "Delete"
+	OperationGet          = "Get"
+	OperationGetOrNil     = "GetOrNil"
+	OperationAll          = "All"
+	OperationCount        = "Count"
+	OperationExists       = "Exists"
+	OperationIter         = "Iter"
+	OperationIterCursor   = "IterCursor"
+	OperationGetPage      = "GetPage"
+	OperationGetPageCount = "GetPageCount"
+)
+
+// RedactedArg replaces argument values when using RedactArgs, and in query logs unless SetLogArgs is enabled
+const RedactedArg = "[REDACTED]"
+
+// QueryEvent describes a query executed by pika.
+// Duration, Rows and Err are only set after the query has been executed.
+type QueryEvent struct {
+	// Operation is the QuerySet method that issued the query, e.g. OperationAll
+	Operation string
+	// Model is the name of the model type
+	Model string
+	// Table is the name of the model table
+	Table string
+	// Query is the generated SQL
+	Query string
+	// Args are the arguments for the query
+	Args []any
+
+	// Duration is the time it took to execute the query.
+	// For iterators, the time spent in the loop body is not included.
+	Duration time.Duration
+	// Rows is the number of rows returned or affected
+	Rows int64
+	// Err is the error returned by the query, if any.
+	// sql.ErrNoRows is not reported, Rows is 0 instead.
+	Err error
+}
+
+// QueryHook is called before and after every query executed by a QuerySet.
+// Can be used for logging, metrics and tracing.
+type QueryHook interface {
+	// BeforeQuery is called before the query is executed.
+	// The returned context is used for the query and passed to AfterQuery.
+	BeforeQuery(ctx context.Context, event *QueryEvent) context.Context
+	// AfterQuery is called after the query has been executed
+	AfterQuery(ctx context.Context, event *QueryEvent)
+}
+
+// AddQueryHook registers a hook that is called for every query on this connection.
+// Hooks are called in the order they are added, and in reverse order for AfterQuery.
+func (p *PostgreSQL) AddQueryHook(hook QueryHook) {
+	p.queryHooks = append(p.queryHooks, hook)
+}
+
+type redactArgsHook struct {
+	hook QueryHook
+}
+
+// RedactArgs wraps a hook so it never sees argument values.
+// Every argument is replaced with RedactedArg.
+func RedactArgs(hook QueryHook) QueryHook {
+	return &redactArgsHook{hook: hook}
+}
+
+// redactArgs returns a RedactedArg for every argument
+func redactArgs(args []any) []any {
+	redacted := make([]any, len(args))
+	for i := range redacted {
+		redacted[i] = RedactedArg
+	}
+
+	return redacted
+}
+
+func (r *redactArgsHook) redact(event *QueryEvent) *QueryEvent {
+	redacted := *event
+	redacted.Args = redactArgs(event.Args)
+
+	return &redacted
+}
+
+func (r *redactArgsHook) BeforeQuery(ctx context.Context, event *QueryEvent) context.Context {
+	return r.hook.BeforeQuery(ctx, r.redact(event))
+}
+
+func (r *redactArgsHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	r.hook.AfterQuery(ctx, r.redact(event))
+}
+
+// queryTimer measures the duration of a query.
+// Iterators pause it while the caller handles a row, so only the query and scanning are measured.
+type queryTimer struct {
+	start   time.Time
+	elapsed time.Duration
+}
+
+func (t *queryTimer) pause() {
+	t.elapsed += time.Since(t.start)
+}
+
+func (t *queryTimer) resume() {
+	t.start = time.Now()
+}
+
+// timedYield returns a yield function that pauses the timer while yield runs
+func timedYield[T any](timer *queryTimer, yield func(*T, error) bool) func(*T, error) bool {
+	return func(x *T, err error) bool {
+		timer.pause()
+		defer timer.resume()
+
+		return yield(x, err)
+	}
+}
+
+// runQuery runs fn with the query hooks of the connection, and logs the query.
+// fn should return the number of rows returned or affected.
+func (b *basePsql[T]) runQuery(ctx context.Context, operation string, q string, args []any, fn func(ctx context.Context) (int64, error)) error {
+	return b.runTimedQuery(ctx, operation, q, args, func(ctx context.Context, _ *queryTimer) (int64, error) {
+		return fn(ctx)
+	})
+}
+
+// runTimedQuery is runQuery for queries that pause the timer, such as iterators
+func (b *basePsql[T]) runTimedQuery(ctx context.Context, operation string, q string, args []any, fn func(ctx context.Context, timer *queryTimer) (int64, error)) error {
+	hooks := b.psql.queryHooks
+	event := &QueryEvent{
+		Operation: operation,
+		Model:     b.metadata[pikaMetadataModelName],
+		Table:     b.metadata[PikaMetadataTableName],
+		Query:     q,
+		Args:      args,
+	}
+	for _, hook := range hooks {
+		ctx = hook.BeforeQuery(ctx, event)
+	}
+
+	timer := &queryTimer{}
+	timer.resume()
+	rows, err := fn(ctx, timer)
+	timer.pause()
+	event.Duration = timer.elapsed
+	event.Rows = rows
+	if !errors.Is(err, sql.ErrNoRows) {
+		event.Err = err
+	}
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i].AfterQuery(ctx, event)
+	}
+
+	b.psql.logQuery(ctx, event)
+
+	return queryError(err)
+}
+
+// getContext runs GetContext with the query hooks
+func (b *basePsql[T]) getContext(ctx context.Context, operation string, dest any, q string, args []any) error {
+	return b.runQuery(ctx, operation, q, args, func(ctx context.Context) (int64, error) {
+		err := b.psql.getContext(ctx, dest, q, args)
+		if err != nil {
+			return 0, err
+		}
+
+		return 1, nil
+	})
+}
@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	pikatestpb "go.ciq.dev/pika/testproto"
+)
+
+type hookContextKey struct{}
+
+type recordingHook struct {
+	before []QueryEvent
+	after  []QueryEvent
+}
+
+func (r *recordingHook) BeforeQuery(ctx context.Context, event *QueryEvent) context.Context {
+	r.before = append(r.before, *event)
+	return context.WithValue(ctx, hookContextKey{}, len(r.before))
+}
+
+func (r *recordingHook) AfterQuery(ctx context.Context, event *QueryEvent) {
+	// The context returned from BeforeQuery is passed on
+	if ctx.Value(hookContextKey{}) != len(r.before) {
+		panic("missing hook context")
+	}
+	r.after = append(r.after, *event)
+}
+
+func (r *recordingHook) operations() []string {
+	var operations []string
+	for _, event := range r.after {
+		operations = append(operations, event.Operation)
+	}
+
+	return operations
+}
+
+func TestQueryHook(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	hook := &recordingHook{}
+	psql.AddQueryHook(hook)
+
+	args := NewArgs()
+	args.Set("id", 2)
+	x, err := Q[simpleModel1](psql).Filter("id=:id").Args(args).Get(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "Test2", x.Title)
+
+	require.Len(t, hook.before, 1)
+	require.Len(t, hook.after, 1)
+	event := hook.after[0]
+	require.Equal(t, OperationGet, event.Operation)
+	require.Equal(t, "simpleModel1", event.Model)
+	require.Equal(t, "simple_model_1", event.Table)
+	require.Equal(t, `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) LIMIT 2`, event.Query)
+	require.Equal(t, []any{2}, event.Args)
+	require.Equal(t, int64(1), event.Rows)
+	require.Nil(t, event.Err)
+	require.NotZero(t, event.Duration)
+}
+
+func TestQueryHookOperations(t *testing.T) {
+	psql := newPsql(t)
+	createTestModelCreate(t, psql)
+	hook := &recordingHook{}
+	psql.AddQueryHook(hook)
+	ctx := context.Background()
+
+	entry := simpleModelCreate{
+		Title:       "test",
+		Description: "test-description",
+	}
+	err := Q[simpleModelCreate](psql).Create(ctx, &entry)
+	require.Nil(t, err)
+
+	entry.Title = "test2"
+	err = Q[simpleModelCreate](psql).F("id", entry.ID).Update(ctx, &entry)
+	require.Nil(t, err)
+
+	ret, err := Q[simpleModelCreate](psql).All(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(ret))
+
+	x, err := Q[simpleModelCreate](psql).F("id", 2).GetOrNil(ctx)
+	require.Nil(t, err)
+	require.Nil(t, x)
+
+	err = Q[simpleModelCreate](psql).F("id", entry.ID).Delete(ctx)
+	require.Nil(t, err)
+
+	require.Equal(t, []string{OperationCreate, OperationUpdate, OperationAll, OperationGetOrNil, OperationDelete}, hook.operations())
+
+	// No rows is not an error
+	require.Equal(t, int64(0), hook.after[3].Rows)
+	require.Nil(t, hook.after[3].Err)
+
+	require.Equal(t, int64(1), hook.after[4].Rows)
+}
+
+func TestQueryHookError(t *testing.T) {
+	psql := newPsql(t)
+	hook := &recordingHook{}
+	psql.AddQueryHook(hook)
+
+	_, err := Q[simpleModel1](psql).InSchema("does_not_exist").All(context.Background())
+	require.NotNil(t, err)
+
+	require.Len(t, hook.after, 1)
+	require.Equal(t, err, hook.after[0].Err)
+}
+
+func TestQueryHookGetPage(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	hook := &recordingHook{}
+	psql.AddQueryHook(hook)
+
+	aipOptions := ProtoReflect(&pikatestpb.SimpleModel1{})
+	req := &pikatestpb.TestRequest1{
+		PageSize: int32(1),
+	}
+	_, _, err := Q[simpleModel1](psql).GetPage(context.Background(), req, aipOptions)
+	require.Nil(t, err)
+
+	require.Equal(t, []string{OperationGetPage, OperationGetPageCount}, hook.operations())
+}
+
+func TestQueryHookIterDuration(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	hook := &recordingHook{}
+	psql.AddQueryHook(hook)
+	ctx := context.Background()
+
+	// The time spent in the loop body is not part of the query
+	body := func(x *simpleModel1, err error) bool {
+		require.Nil(t, err)
+		time.Sleep(100 * time.Millisecond)
+		return true
+	}
+	Q[simpleModel1](psql).Iter(ctx)(body)
+	Q[simpleModel1](psql).IterCursor(ctx, 2)(body)
+
+	require.Equal(t, []string{OperationIter, OperationIterCursor}, hook.operations())
+	for _, event := range hook.after {
+		require.Equal(t, int64(3), event.Rows)
+		require.Less(t, event.Duration, 300*time.Millisecond, event.Operation)
+	}
+}
+
+func TestTimedYield(t *testing.T) {
+	timer := &queryTimer{}
+	timer.resume()
+	yield := timedYield(timer, func(*simpleModel1, error) bool {
+		time.Sleep(50 * time.Millisecond)
+		return true
+	})
+	require.True(t, yield(nil, nil))
+	timer.pause()
+
+	require.Less(t, timer.elapsed, 50*time.Millisecond)
+}
+
+func TestRedactArgs(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	hook := &recordingHook{}
+	psql.AddQueryHook(RedactArgs(hook))
+
+	args := NewArgs()
+	args.Set("title", "secret")
+	_, err := Q[simpleModel1](psql).Filter("title=:title").Args(args).All(context.Background())
+	require.Nil(t, err)
+
+	require.Len(t, hook.before, 1)
+	require.Equal(t, []any{RedactedArg}, hook.before[0].Args)
+	require.Len(t, hook.after, 1)
+	require.Equal(t, []any{RedactedArg}, hook.after[0].Args)
+}
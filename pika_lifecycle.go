@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+)
+
+// Models can implement the following interfaces to hook into the lifecycle of a query.
+// The hooks are called on a pointer to the model, and an error aborts the operation.
+// After hooks are called after the query, use a transaction to roll back the changes.
+
+// BeforeCreator is called before a model is created
+type BeforeCreator interface {
+	BeforeCreate(ctx context.Context) error
+}
+
+// AfterCreator is called after a model is created
+type AfterCreator interface {
+	AfterCreate(ctx context.Context) error
+}
+
+// BeforeUpdater is called before a model is updated
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// AfterUpdater is called after a model is updated
+type AfterUpdater interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+// BeforeDeleter is called before a model is deleted.
+// Delete loads the matching rows first to call the hook for each of them.
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+// AfterFinder is called after a model is loaded by Get, GetOrNil, All, Iter or GetPage
+type AfterFinder interface {
+	AfterFind(ctx context.Context) error
+}
+
+// Validator is called before a model is created or updated, after BeforeCreate and BeforeUpdate
+type Validator interface {
+	Validate() error
+}
+
+func beforeCreate(ctx context.Context, x any) error {
+	if hook, ok := x.(BeforeCreator); ok {
+		err := hook.BeforeCreate(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return validate(x)
+}
+
+func afterCreate(ctx context.Context, x any) error {
+	if hook, ok := x.(AfterCreator); ok {
+		return hook.AfterCreate(ctx)
+	}
+
+	return nil
+}
+
+func beforeUpdate(ctx context.Context, x any) error {
+	if hook, ok := x.(BeforeUpdater); ok {
+		err := hook.BeforeUpdate(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return validate(x)
+}
+
+func afterUpdate(ctx context.Context, x any) error {
+	if hook, ok := x.(AfterUpdater); ok {
+		return hook.AfterUpdate(ctx)
+	}
+
+	return nil
+}
+
+func beforeDelete(ctx context.Context, x any) error {
+	if hook, ok := x.(BeforeDeleter); ok {
+		return hook.BeforeDelete(ctx)
+	}
+
+	return nil
+}
+
+func afterFind(ctx context.Context, x any) error {
+	if hook, ok := x.(AfterFinder); ok {
+		return hook.AfterFind(ctx)
+	}
+
+	return nil
+}
+
+func validate(x any) error {
+	if validator, ok := x.(Validator); ok {
+		return validator.Validate()
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type lifecycleContextKey struct{}
+
+var errEmptyTitle = errors.New("title is empty")
+
+type lifecycleModel struct {
+	PikaTableName string `pika:"simple_model_create"`
+
+	ID          int    `db:"id" pika:"omitempty"`
+	Title       string `db:"title"`
+	Description string `db:"description"`
+}
+
+func recordLifecycle(ctx context.Context, x *lifecycleModel, hook string) {
+	calls := ctx.Value(lifecycleContextKey{}).(*[]string)
+	*calls = append(*calls, fmt.Sprintf("%s:%d", hook, x.ID))
+}
+
+func (x *lifecycleModel) BeforeCreate(ctx context.Context) error {
+	recordLifecycle(ctx, x, "BeforeCreate")
+	x.Title = strings.TrimSpace(x.Title)
+	return nil
+}
+
+func (x *lifecycleModel) AfterCreate(ctx context.Context) error {
+	recordLifecycle(ctx, x, "AfterCreate")
+	return nil
+}
+
+func (x *lifecycleModel) BeforeUpdate(ctx context.Context) error {
+	recordLifecycle(ctx, x, "BeforeUpdate")
+	return nil
+}
+
+func (x *lifecycleModel) AfterUpdate(ctx context.Context) error {
+	recordLifecycle(ctx, x, "AfterUpdate")
+	return nil
+}
+
+func (x *lifecycleModel) BeforeDelete(ctx context.Context) error {
+	recordLifecycle(ctx, x, "BeforeDelete")
+	if x.Description == "keep" {
+		return errors.New("can't delete")
+	}
+	return nil
+}
+
+func (x *lifecycleModel) AfterFind(ctx context.Context) error {
+	recordLifecycle(ctx, x, "AfterFind")
+	return nil
+}
+
+func (x *lifecycleModel) Validate() error {
+	if x.Title == "" {
+		return errEmptyTitle
+	}
+	return nil
+}
+
+func newLifecycleContext() (context.Context, *[]string) {
+	calls := &[]string{}
+	return context.WithValue(context.Background(), lifecycleContextKey{}, calls), calls
+}
+
+func TestLifecycleCreateUpdate(t *testing.T) {
+	psql := newPsql(t)
+	createTestModelCreate(t, psql)
+	ctx, calls := newLifecycleContext()
+
+	entry := lifecycleModel{
+		Title:       "  test  ",
+		Description: "test-description",
+	}
+	err := Q[lifecycleModel](psql).Create(ctx, &entry)
+	require.Nil(t, err)
+	require.Equal(t, "test", entry.Title)
+
+	entry.Title = "test2"
+	err = Q[lifecycleModel](psql).U(ctx, &entry)
+	require.Nil(t, err)
+
+	require.Equal(t, []string{"BeforeCreate:0", "AfterCreate:1", "BeforeUpdate:1", "AfterUpdate:1"}, *calls)
+
+	// Validation errors abort the operation
+	*calls = nil
+	entry.Title = ""
+	err = Q[lifecycleModel](psql).U(ctx, &entry)
+	require.ErrorIs(t, err, errEmptyTitle)
+	require.Equal(t, []string{"BeforeUpdate:1"}, *calls)
+
+	err = Q[lifecycleModel](psql).Create(ctx, &lifecycleModel{Title: " "})
+	require.ErrorIs(t, err, errEmptyTitle)
+
+	count, err := Q[lifecycleModel](psql).Count(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestLifecycleFind(t *testing.T) {
+	psql := newPsql(t)
+	createTestModelCreate(t, psql)
+	psql.db.MustExec("INSERT INTO simple_model_create (title, description) VALUES ('a', 'a'), ('b', 'b')")
+	ctx, calls := newLifecycleContext()
+
+	ret, err := Q[lifecycleModel](psql).OrderBy("id").All(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(ret))
+
+	x, err := Q[lifecycleModel](psql).F("id", 2).Get(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "b", x.Title)
+
+	Q[lifecycleModel](psql).OrderBy("id").Iter(ctx)(func(x *lifecycleModel, err error) bool {
+		require.Nil(t, err)
+		return true
+	})
+
+	require.Equal(t, []string{"AfterFind:1", "AfterFind:2", "AfterFind:2", "AfterFind:1", "AfterFind:2"}, *calls)
+}
+
+func TestLifecycleDelete(t *testing.T) {
+	psql := newPsql(t)
+	createTestModelCreate(t, psql)
+	psql.db.MustExec("INSERT INTO simple_model_create (title, description) VALUES ('a', 'a'), ('b', 'keep'), ('c', 'c')")
+	ctx, calls := newLifecycleContext()
+
+	err := Q[lifecycleModel](psql).F("id", 1).Delete(ctx)
+	require.Nil(t, err)
+	require.Equal(t, []string{"BeforeDelete:1"}, *calls)
+
+	// An error in BeforeDelete aborts the delete
+	*calls = nil
+	args := NewArgs()
+	args.Set("id", 1)
+	err = Q[lifecycleModel](psql).Filter("id__gt=:id").Args(args).OrderBy("id").Delete(ctx)
+	require.NotNil(t, err)
+	require.Equal(t, []string{"BeforeDelete:2"}, *calls)
+
+	// D calls the hook on the given value
+	*calls = nil
+	err = Q[lifecycleModel](psql).D(ctx, &lifecycleModel{ID: 3})
+	require.Nil(t, err)
+	require.Equal(t, []string{"BeforeDelete:3"}, *calls)
+
+	count, err := Q[lifecycleModel](psql).Count(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 1, count)
+}
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SetLogger sets the logger for this connection.
+// Executed queries are logged at debug level, slow queries at warn level.
+// If not set, the package logger is used, which logs queries if PIKA_DEBUG=1.
+func (p *PostgreSQL) SetLogger(logger *slog.Logger) {
+	p.logger = logger
+}
+
+// SetSlowQueryThreshold sets the duration after which a query is logged at warn level.
+// Argument values are not included in slow query logs.
+// Zero disables slow query logging.
+func (p *PostgreSQL) SetSlowQueryThreshold(threshold time.Duration) {
+	p.slowQueryThreshold = threshold
+}
+
+// SetLogArgs sets whether argument values are included in debug query logs.
+// Arguments are replaced with RedactedArg by default, as they may contain secrets.
+func (p *PostgreSQL) SetLogArgs(enabled bool) {
+	p.logArgs = enabled
+}
+
+// logQuery logs an executed query
+func (c *connBase) logQuery(ctx context.Context, event *QueryEvent) {
+	fields := []any{
+		"operation", event.Operation,
+		"model", event.Model,
+		"query", event.Query,
+		"duration", event.Duration,
+		"rows", event.Rows,
+	}
+	if event.Err != nil {
+		fields = append(fields, "error", event.Err)
+	}
+
+	if c.slowQueryThreshold > 0 && event.Duration >= c.slowQueryThreshold {
+		slowFields := make([]any, 0, len(fields)+2)
+		slowFields = append(slowFields, fields...)
+		slowFields = append(slowFields, "threshold", c.slowQueryThreshold)
+		c.log(ctx, slog.LevelWarn, "Pika slow query", slowFields...)
+	}
+
+	args := event.Args
+	if !c.logArgs {
+		args = redactArgs(args)
+	}
+	c.log(ctx, slog.LevelDebug, "Pika query", append(fields, "args", args)...)
+}
+
+// logDebug logs a message at debug level
+func (c *connBase) logDebug(ctx context.Context, msg string, fields ...any) {
+	c.log(ctx, slog.LevelDebug, msg, fields...)
+}
+
+// log logs a message with key-value pairs.
+// Falls back to the package logger if no logger is set.
+func (c *connBase) log(ctx context.Context, level slog.Level, msg string, fields ...any) {
+	if c.logger != nil {
+		c.logger.Log(ctx, level, msg, fields...)
+		return
+	}
+
+	logrusFields := make(logrus.Fields, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		if key, ok := fields[i].(string); ok {
+			logrusFields[key] = fields[i+1]
+		}
+	}
+
+	entry := logger.WithContext(ctx).WithFields(logrusFields)
+	if level >= slog.LevelWarn {
+		entry.Warn(msg)
+		return
+	}
+	entry.Debug(msg)
+}
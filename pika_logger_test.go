@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(buf *bytes.Buffer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: level}))
+}
+
+func readLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var x map[string]any
+		require.Nil(t, json.Unmarshal([]byte(line), &x))
+		lines = append(lines, x)
+	}
+
+	return lines
+}
+
+func TestLogger(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	var buf bytes.Buffer
+	psql.SetLogger(newTestLogger(&buf, slog.LevelDebug))
+
+	args := NewArgs()
+	args.Set("title", "Test2")
+	_, err := Q[simpleModel1](psql).Filter("title=:title").Args(args).All(context.Background())
+	require.Nil(t, err)
+
+	lines := readLogLines(t, &buf)
+	require.Len(t, lines, 1)
+	require.Equal(t, "DEBUG", lines[0]["level"])
+	require.Equal(t, "Pika query", lines[0]["msg"])
+	require.Equal(t, OperationAll, lines[0]["operation"])
+	require.Equal(t, "simpleModel1", lines[0]["model"])
+	require.Equal(t, `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."title" = $1)`, lines[0]["query"])
+	require.Equal(t, []any{RedactedArg}, lines[0]["args"])
+	require.Equal(t, float64(1), lines[0]["rows"])
+	require.Contains(t, lines[0], "duration")
+
+	// Argument values are only logged if enabled
+	buf.Reset()
+	psql.SetLogArgs(true)
+	_, err = Q[simpleModel1](psql).Filter("title=:title").Args(args).All(context.Background())
+	require.Nil(t, err)
+
+	lines = readLogLines(t, &buf)
+	require.Len(t, lines, 1)
+	require.Equal(t, []any{"Test2"}, lines[0]["args"])
+}
+
+func TestLoggerSlowQuery(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	var buf bytes.Buffer
+	psql.SetLogger(newTestLogger(&buf, slog.LevelWarn))
+	psql.SetSlowQueryThreshold(time.Nanosecond)
+
+	args := NewArgs()
+	args.Set("title", "secret")
+	_, err := Q[simpleModel1](psql).Filter("title=:title").Args(args).All(context.Background())
+	require.Nil(t, err)
+
+	lines := readLogLines(t, &buf)
+	require.Len(t, lines, 1)
+	require.Equal(t, "WARN", lines[0]["level"])
+	require.Equal(t, "Pika slow query", lines[0]["msg"])
+	require.Equal(t, OperationAll, lines[0]["operation"])
+	require.Contains(t, lines[0], "threshold")
+	require.NotContains(t, lines[0], "args")
+
+	// Fast queries are not logged at warn level
+	buf.Reset()
+	psql.SetSlowQueryThreshold(time.Hour)
+	_, err = Q[simpleModel1](psql).All(context.Background())
+	require.Nil(t, err)
+	require.Empty(t, buf.String())
+}
@@ -0,0 +1,233 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gertd/go-pluralize"
+	"github.com/iancoleman/strcase"
+)
+
+const (
+	// pikaTagOmitEmpty skips the column on insert and update if the value is empty
+	pikaTagOmitEmpty = "omitempty"
+	// pikaTagPrimaryKey marks the column as part of the primary key
+	pikaTagPrimaryKey = "pk"
+	// pikaTagUnique adds a unique constraint to the column in DDL
+	pikaTagUnique = "unique"
+	// pikaTagIndex adds an index on the column in DDL
+	pikaTagIndex = "index"
+	// pikaTagDefault sets the default expression of the column in DDL, for example default=NOW()
+	pikaTagDefault = "default"
+	// pikaTagType overrides the SQL type of the column in DDL, for example type=VARCHAR(100)
+	pikaTagType = "type"
+)
+
+// models caches the ModelInfo of each model type
+var models sync.Map
+
+// pluralizeClient is shared, as creating a client is expensive
+var pluralizeClient = pluralize.NewClient()
+
+// ModelInfo describes the table and columns of a model.
+// It is computed once per model type and must not be modified.
+type ModelInfo struct {
+	// Name is the name of the model type
+	Name string
+	// TableName is the table set with PikaTableName, or the pluralized snake cased model name.
+	// Table aliases set on a connection are not applied.
+	TableName string
+	// DefaultOrderBy is the order set with PikaDefaultOrderBy
+	DefaultOrderBy string
+	// TenantColumn is the column set with PikaTenantColumn
+	TenantColumn string
+	// Columns are the fields with a db tag, in field order
+	Columns []*ColumnInfo
+	// PrimaryKey are the columns tagged with pk in the pika tag, in field order.
+	// Defaults to the id column if no column is tagged.
+	PrimaryKey []string
+
+	explicitTableName bool
+	metadata          map[string]string
+}
+
+// ColumnInfo describes a column of a model
+type ColumnInfo struct {
+	// Name is the column name from the db tag
+	Name string
+	// Field is the name of the struct field
+	Field string
+	// Index is the index of the struct field
+	Index int
+	// Type is the type of the struct field
+	Type reflect.Type
+	// PikaName is the name used in Include and Exclude, the db tag unless set in the pika tag.
+	// A name in the form of table.column selects the column from another table.
+	PikaName string
+	// OmitEmpty skips the column on insert and update if the value is empty
+	OmitEmpty bool
+	// PrimaryKey is true if the column is part of the primary key
+	PrimaryKey bool
+	// Unique is true if the column has a unique constraint
+	Unique bool
+	// Indexed is true if the column is indexed
+	Indexed bool
+	// Default is the default expression of the column
+	Default string
+	// SQLType is the SQL type of the column if set in the pika tag.
+	// Otherwise the type is inferred from the Go type.
+	SQLType string
+}
+
+// Model returns the ModelInfo for the model type T
+func Model[T any]() *ModelInfo {
+	return modelOf(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// modelOf returns the ModelInfo for the model type t
+func modelOf(t reflect.Type) *ModelInfo {
+	if info, ok := models.Load(t); ok {
+		return info.(*ModelInfo)
+	}
+
+	info, _ := models.LoadOrStore(t, newModelInfo(t))
+	return info.(*ModelInfo)
+}
+
+// Column returns the column with the given name, or nil if not found
+func (m *ModelInfo) Column(name string) *ColumnInfo {
+	for _, column := range m.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+
+	return nil
+}
+
+func newModelInfo(t reflect.Type) *ModelInfo {
+	info := &ModelInfo{
+		Name:     t.Name(),
+		metadata: map[string]string{},
+	}
+	info.metadata[pikaMetadataModelName] = info.Name
+
+	// Iterate through fields to get tags
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		// We only care about fields starting with "Pika"
+		if strings.HasPrefix(field.Name, "Pika") && contains(PikaMetadataFields, field.Name) {
+			tag := field.Tag.Get("pika")
+
+			if _, ok := info.metadata[field.Name]; ok {
+				panic("duplicate Pika metadata field: " + field.Name)
+			}
+			info.metadata[field.Name] = tag
+
+			switch field.Name {
+			case PikaMetadataTableName:
+				info.TableName = tag
+				info.explicitTableName = tag != ""
+			case PikaMetadataDefaultOrderBy:
+				info.DefaultOrderBy = tag
+			case PikaMetadataTenantColumn:
+				info.TenantColumn = tag
+			}
+			continue
+		}
+
+		// Ignore empty or "-" tags
+		tag := field.Tag.Get("db")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		if !strings.HasPrefix(field.Name, "Pika") {
+			if _, ok := info.metadata[field.Name]; ok {
+				panic("duplicate Pika database field: " + field.Name)
+			}
+
+			// This is a regular field, let's store information about it's type
+			info.metadata[tag] = field.Type.String()
+		}
+
+		pikaName, options := parsePikaTag(field.Tag.Get("pika"))
+		if pikaName == "" {
+			pikaName = tag
+		}
+
+		info.Columns = append(info.Columns, &ColumnInfo{
+			Name:       tag,
+			Field:      field.Name,
+			Index:      i,
+			Type:       field.Type,
+			PikaName:   pikaName,
+			OmitEmpty:  contains(options, pikaTagOmitEmpty),
+			PrimaryKey: contains(options, pikaTagPrimaryKey),
+			Unique:     contains(options, pikaTagUnique),
+			Indexed:    contains(options, pikaTagIndex),
+			Default:    pikaTagValue(options, pikaTagDefault),
+			SQLType:    pikaTagValue(options, pikaTagType),
+		})
+	}
+
+	for _, column := range info.Columns {
+		if column.PrimaryKey {
+			info.PrimaryKey = append(info.PrimaryKey, column.Name)
+		}
+	}
+
+	// Models without a pk tag use the id column
+	if len(info.PrimaryKey) == 0 {
+		if column := info.Column("id"); column != nil {
+			column.PrimaryKey = true
+			info.PrimaryKey = []string{column.Name}
+		}
+	}
+
+	if info.TableName == "" {
+		info.TableName = strcase.ToSnake(pluralizeClient.Plural(info.Name))
+		info.metadata[PikaMetadataTableName] = info.TableName
+	}
+
+	return info
+}
+
+// parsePikaTag splits a pika tag into the name and options.
+// For example, "pk,omitempty" has no name, and "other.id,omitempty" has the name other.id.
+// Options can have a value, for example "default=NOW()". Values can't contain commas.
+func parsePikaTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+	option, _, _ := strings.Cut(parts[0], "=")
+	if contains(pikaTagOptions, option) {
+		return "", parts
+	}
+
+	return parts[0], parts[1:]
+}
+
+// pikaTagValue returns the value of an option in the form of name=value, or an empty string
+func pikaTagValue(options []string, name string) string {
+	for _, option := range options {
+		if value, ok := strings.CutPrefix(option, name+"="); ok {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// pikaTagOptions are the options allowed in the pika tag of a field
+var pikaTagOptions = []string{
+	pikaTagOmitEmpty,
+	pikaTagPrimaryKey,
+	pikaTagUnique,
+	pikaTagIndex,
+	pikaTagDefault,
+	pikaTagType,
+}
@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel(t *testing.T) {
+	info := Model[simpleModelCreate]()
+	require.Equal(t, "simpleModelCreate", info.Name)
+	require.Equal(t, "simple_model_create", info.TableName)
+	require.Equal(t, []string{"id"}, info.PrimaryKey)
+
+	require.Len(t, info.Columns, 3)
+	require.Equal(t, &ColumnInfo{
+		Name:       "id",
+		Field:      "ID",
+		Index:      1,
+		Type:       reflect.TypeOf(0),
+		PikaName:   "id",
+		OmitEmpty:  true,
+		PrimaryKey: true,
+	}, info.Columns[0])
+	require.Equal(t, "title", info.Columns[1].Name)
+	require.False(t, info.Columns[1].OmitEmpty)
+
+	require.Equal(t, info.Columns[2], info.Column("description"))
+	require.Nil(t, info.Column("does_not_exist"))
+
+	info = Model[simpleModel2]()
+	require.Equal(t, "-created_at", info.DefaultOrderBy)
+
+	info = Model[joinSimpleModelMain]()
+	require.Equal(t, "join_model_foreign.id", info.Columns[2].PikaName)
+
+	info = Model[tenantModel]()
+	require.Equal(t, "tenant_id", info.TenantColumn)
+}
+
+func TestModelPluralTableName(t *testing.T) {
+	info := Model[noExplicitTableName]()
+	require.Equal(t, "no_explicit_table_names", info.TableName)
+	require.Empty(t, info.DefaultOrderBy)
+}
+
+func TestModelCached(t *testing.T) {
+	var wg sync.WaitGroup
+	infos := make([]*ModelInfo, 10)
+	for i := range infos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			infos[i] = Model[simpleModel1]()
+		}()
+	}
+	wg.Wait()
+
+	for _, info := range infos {
+		require.Same(t, Model[simpleModel1](), info)
+	}
+}
+
+func TestModelTableAlias(t *testing.T) {
+	psql := newPsql(t)
+	psql.TableAlias("noExplicitTableName", "alias_table")
+
+	tableName := PSQLQuery[noExplicitTableName](psql).(*basePsql[noExplicitTableName]).metadata[PikaMetadataTableName]
+	require.Equal(t, "alias_table", tableName)
+
+	// The alias is not applied to the cached model or other connections
+	require.Equal(t, "no_explicit_table_names", Model[noExplicitTableName]().TableName)
+	tableName = PSQLQuery[noExplicitTableName](newPsql(t)).(*basePsql[noExplicitTableName]).metadata[PikaMetadataTableName]
+	require.Equal(t, "no_explicit_table_names", tableName)
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Static errors for err113 compliance
+var (
+	ErrPrimaryKeyCount = errors.New("wrong number of primary key values")
+)
+
+// primaryKey returns the primary key columns of the model.
+// Returns ErrIDNotFound if the model has no primary key.
+func (b *basePsql[T]) primaryKey() ([]string, error) {
+	if len(b.model.PrimaryKey) == 0 {
+		return nil, fmt.Errorf("%w: %s has no primary key", ErrIDNotFound, b.model.Name)
+	}
+
+	return b.model.PrimaryKey, nil
+}
+
+// primaryKeyValues returns the primary key values of x, in the order of the primary key columns
+func (b *basePsql[T]) primaryKeyValues(x *T) ([]any, error) {
+	pk, err := b.primaryKey()
+	if err != nil {
+		return nil, err
+	}
+
+	elem := reflect.ValueOf(x).Elem()
+	values := make([]any, 0, len(pk))
+	for _, name := range pk {
+		values = append(values, elem.Field(b.model.Column(name).Index).Interface())
+	}
+
+	return values, nil
+}
+
+// filterPrimaryKey filters the query set on the given primary key values
+func (b *basePsql[T]) filterPrimaryKey(values []any) (QuerySet[T], error) {
+	pk, err := b.primaryKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) != len(pk) {
+		return nil, fmt.Errorf("%w: expected %d (%s), got %d", ErrPrimaryKeyCount, len(pk), strings.Join(pk, ", "), len(values))
+	}
+
+	keyval := make([]any, 0, len(pk)*2)
+	for i, name := range pk {
+		keyval = append(keyval, name, values[i])
+	}
+
+	return b.F(keyval...), nil
+}
+
+// GetByPK returns the value with the given primary key
+func (b *basePsql[T]) GetByPK(ctx context.Context, keys ...any) (*T, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	qs, err := b.filterPrimaryKey(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return qs.Get(ctx)
+}
+
+// orderByPrimaryKey appends the primary key columns that are not already part of the order.
+// Makes the order unique, so rows are not skipped or repeated between pages.
+func (b *basePsql[T]) orderByPrimaryKey() {
+	if len(b.model.PrimaryKey) == 0 {
+		return
+	}
+
+	orderBy := make([]string, 0, len(b.orderBy)+len(b.model.PrimaryKey))
+	orderBy = append(orderBy, b.orderBy...)
+	if len(orderBy) == 0 {
+		if defaultOrderBy := b.metadata[PikaMetadataDefaultOrderBy]; defaultOrderBy != "" {
+			orderBy = append(orderBy, defaultOrderBy)
+		}
+	}
+
+	ordered := make([]string, 0, len(orderBy))
+	for _, o := range orderBy {
+		ordered = append(ordered, strings.TrimPrefix(o, "-"))
+	}
+	for _, name := range b.model.PrimaryKey {
+		if !contains(ordered, name) {
+			orderBy = append(orderBy, name)
+		}
+	}
+
+	b.setOrderBy(orderBy, true)
+}
@@ -0,0 +1,213 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type membershipModel struct {
+	PikaTableName string `pika:"membership_model"`
+
+	OrgID  int    `db:"org_id" pika:"pk"`
+	UserID int    `db:"user_id" pika:"pk"`
+	Role   string `db:"role"`
+}
+
+type codeModel struct {
+	PikaTableName string `pika:"code_model"`
+
+	Code string `db:"code" pika:"pk"`
+	Name string `db:"name"`
+}
+
+type noPrimaryKeyModel struct {
+	PikaTableName string `pika:"no_primary_key_model"`
+
+	Name string `db:"name"`
+}
+
+func createMembershipEntries(t *testing.T, psql *PostgreSQL) {
+	psql.db.MustExec("DROP TABLE IF EXISTS membership_model")
+	psql.db.MustExec("CREATE TABLE membership_model (org_id INT NOT NULL, user_id INT NOT NULL, role TEXT, PRIMARY KEY (org_id, user_id))")
+	psql.db.MustExec(`
+		INSERT INTO membership_model (org_id, user_id, role)
+		VALUES
+		(1, 1, 'admin'),
+		(1, 2, 'member'),
+		(2, 1, 'member')
+	`)
+}
+
+func createCodeEntries(t *testing.T, psql *PostgreSQL) {
+	psql.db.MustExec("DROP TABLE IF EXISTS code_model")
+	psql.db.MustExec("CREATE TABLE code_model (code TEXT PRIMARY KEY, name TEXT)")
+	psql.db.MustExec(`
+		INSERT INTO code_model (code, name)
+		VALUES
+		('a', 'Test'),
+		('b', 'Test2'),
+		('c', 'Test3')
+	`)
+}
+
+func TestModelPrimaryKey(t *testing.T) {
+	info := Model[membershipModel]()
+	require.Equal(t, []string{"org_id", "user_id"}, info.PrimaryKey)
+	require.True(t, info.Column("org_id").PrimaryKey)
+	require.True(t, info.Column("user_id").PrimaryKey)
+	require.False(t, info.Column("role").PrimaryKey)
+	require.Equal(t, "org_id", info.Column("org_id").PikaName)
+
+	require.Equal(t, []string{"code"}, Model[codeModel]().PrimaryKey)
+	require.Equal(t, []string{"id"}, Model[noExplicitTableName]().PrimaryKey)
+	require.Empty(t, Model[noPrimaryKeyModel]().PrimaryKey)
+}
+
+func TestGetByPK(t *testing.T) {
+	psql := newPsql(t)
+	createMembershipEntries(t, psql)
+	ctx := context.Background()
+
+	x, err := Q[membershipModel](psql).GetByPK(ctx, 1, 2)
+	require.Nil(t, err)
+	require.Equal(t, "member", x.Role)
+
+	_, err = Q[membershipModel](psql).GetByPK(ctx, 1)
+	require.ErrorIs(t, err, ErrPrimaryKeyCount)
+
+	_, err = Q[noPrimaryKeyModel](psql).GetByPK(ctx, "Test")
+	require.ErrorIs(t, err, ErrIDNotFound)
+}
+
+func TestUpdateQueryPrimaryKey(t *testing.T) {
+	psql := newPsql(t)
+
+	x := &membershipModel{OrgID: 1, UserID: 2, Role: "admin"}
+	qs := Q[membershipModel](psql).F("org_id", x.OrgID, "user_id", x.UserID)
+
+	expectedQuery := `UPDATE "membership_model" SET "role" = $3 WHERE ("org_id" = $1 AND "user_id" = $2) RETURNING "org_id", "user_id", "role"`
+	expectedArgs := []interface{}{1, 2, "admin"}
+	actualQuery, actualArgs := qs.UpdateQuery(x)
+	require.Equal(t, expectedQuery, actualQuery)
+	require.Equal(t, expectedArgs, actualArgs)
+}
+
+func TestUPrimaryKey(t *testing.T) {
+	psql := newPsql(t)
+	createMembershipEntries(t, psql)
+	ctx := context.Background()
+
+	x, err := Q[membershipModel](psql).GetByPK(ctx, 2, 1)
+	require.Nil(t, err)
+
+	x.Role = "admin"
+	err = Q[membershipModel](psql).U(ctx, x)
+	require.Nil(t, err)
+
+	ret, err := Q[membershipModel](psql).F("role", "admin").OrderBy("org_id").All(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(ret))
+	require.Equal(t, 2, ret[1].OrgID)
+	require.Equal(t, 1, ret[1].UserID)
+}
+
+func TestDPrimaryKey(t *testing.T) {
+	psql := newPsql(t)
+	createMembershipEntries(t, psql)
+	ctx := context.Background()
+
+	err := Q[membershipModel](psql).D(ctx, &membershipModel{OrgID: 1, UserID: 1})
+	require.Nil(t, err)
+
+	ret, err := Q[membershipModel](psql).All(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(ret))
+
+	x, err := Q[membershipModel](psql).GetByPK(ctx, 1, 1)
+	require.NotNil(t, err)
+	require.Nil(t, x)
+}
+
+func TestGetPagePrimaryKeyOrder(t *testing.T) {
+	psql := newPsql(t)
+	createMembershipEntries(t, psql)
+	hook := &recordingHook{}
+	psql.AddQueryHook(hook)
+
+	req := &PageRequest{
+		PageSize: 2,
+	}
+	page, nt, err := Q[membershipModel](psql).GetPage(context.Background(), req, AIPFilterOptions{})
+	require.Nil(t, err)
+	require.NotEmpty(t, nt)
+	require.Equal(t, 2, len(page))
+	require.Equal(t, 1, page[1].OrgID)
+	require.Equal(t, 2, page[1].UserID)
+
+	require.Contains(t, hook.after[0].Query, `ORDER BY "membershipModel"."org_id" ASC, "membershipModel"."user_id" ASC`)
+
+	// The primary key is only appended if not already part of the order
+	req = &PageRequest{
+		PageSize: 2,
+		OrderBy:  "user_id desc",
+	}
+	options := AIPFilterOptions{
+		AcceptableIdentifiers: []string{"user_id"},
+	}
+	page, _, err = Q[membershipModel](psql).GetPage(context.Background(), req, options)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(page))
+	require.Equal(t, 1, page[0].OrgID)
+	require.Equal(t, 2, page[0].UserID)
+	require.Equal(t, 1, page[1].OrgID)
+	require.Equal(t, 1, page[1].UserID)
+}
+
+func TestChunkedPrimaryKey(t *testing.T) {
+	psql := newPsql(t)
+	createCodeEntries(t, psql)
+
+	var codes []string
+	err := Q[codeModel](psql).Chunked(context.Background(), 2, func(x []*codeModel) error {
+		for _, v := range x {
+			codes = append(codes, v.Code)
+		}
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{"a", "b", "c"}, codes)
+
+	createMembershipEntries(t, psql)
+	var keys [][]int
+	err = Q[membershipModel](psql).Chunked(context.Background(), 2, func(x []*membershipModel) error {
+		for _, v := range x {
+			keys = append(keys, []int{v.OrgID, v.UserID})
+		}
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, [][]int{{1, 1}, {1, 2}, {2, 1}}, keys)
+}
+
+func TestKeysetQuery(t *testing.T) {
+	psql := newPsql(t)
+
+	args := NewArgs()
+	args.Set("role", "member")
+	qs := Q[membershipModel](psql).Filter("role=:role").Args(args)
+	qs.(*basePsql[membershipModel]).setKeyset([]string{"org_id", "user_id"}, []any{1, 2})
+	query, queryArgs := qs.AllQuery()
+	require.Equal(t, `SELECT "membershipModel"."org_id", "membershipModel"."user_id", "membershipModel"."role" FROM "membership_model" "membershipModel" WHERE ("membershipModel"."role" = $1) AND ("membershipModel"."org_id", "membershipModel"."user_id") > ($2, $3)`, query)
+	require.Equal(t, []any{"member", 1, 2}, queryArgs)
+
+	codes := Q[codeModel](psql)
+	codes.(*basePsql[codeModel]).setKeyset([]string{"code"}, []any{"b"})
+	query, queryArgs = codes.AllQuery()
+	require.Equal(t, `SELECT "codeModel"."code", "codeModel"."name" FROM "code_model" "codeModel" WHERE "codeModel"."code" > $1`, query)
+	require.Equal(t, []any{"b"}, queryArgs)
+}
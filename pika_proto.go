@@ -0,0 +1,706 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.ciq.dev/pika/pikapb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// Static errors for err113 compliance
+var (
+	ErrProtoTypeMismatch  = errors.New("protobuf field does not match model field")
+	ErrNumericOverflow    = errors.New("value is out of range")
+	ErrProtoTableMismatch = errors.New("protobuf message is stored in another table")
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	valuerType   = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+// protoField is a protobuf field with the model column it maps to
+type protoField struct {
+	// parents are the flattened messages containing the field, outermost first
+	parents []protoreflect.FieldDescriptor
+	fd      protoreflect.FieldDescriptor
+	column  *ColumnInfo
+	// json is true if the field is a message stored as JSON in the column
+	json bool
+}
+
+// protoFields returns the fields of a message that map to a column of the model.
+// Nested messages are flattened or stored in a JSONB column the same way as in ProtoReflect,
+// for example spec.image maps to the spec_image column.
+// Excluded fields and fields not in the field mask of the options are skipped.
+func protoFields(desc protoreflect.MessageDescriptor, info *ModelInfo, opts ProtoReflectOptions) []protoField {
+	var res []protoField
+	appendProtoFields(&res, desc, info, opts, nil, "", "")
+
+	return res
+}
+
+func appendProtoFields(res *[]protoField, desc protoreflect.MessageDescriptor, info *ModelInfo, opts ProtoReflectOptions, parents []protoreflect.FieldDescriptor, path string, prefix string) {
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		fieldPath := path + string(fd.Name())
+		if contains(opts.Exclude, fieldPath) || !inFieldMask(opts.FieldMask, fieldPath) {
+			continue
+		}
+
+		name := prefix + protoColumnName(fd, opts)
+		fieldOpts, _ := proto.GetExtension(fd.Options(), pikapb.E_Field).(*pikapb.FieldOptions)
+		json := fieldOpts.GetJsonb() && fd.Kind() == protoreflect.MessageKind && !fd.IsList() && !fd.IsMap()
+
+		// Flattened messages are recursed into while the model has columns for them,
+		// which also ends recursive messages
+		if protoNestedMessage(fd) && !fd.IsList() && !fd.IsMap() && !json {
+			if slices.ContainsFunc(info.Columns, func(c *ColumnInfo) bool { return strings.HasPrefix(c.Name, name+"_") }) {
+				appendProtoFields(res, fd.Message(), info, opts, append(slices.Clip(parents), fd), fieldPath+".", name+"_")
+			}
+			continue
+		}
+
+		column := info.Column(name)
+		if column == nil {
+			continue
+		}
+
+		*res = append(*res, protoField{parents: parents, fd: fd, column: column, json: json})
+	}
+}
+
+// checkProtoTable returns ErrProtoTableMismatch if the (pika.resource) table of a message
+// is set and is not the table of the model
+func checkProtoTable(desc protoreflect.MessageDescriptor, info *ModelInfo) error {
+	table := protoTableName(desc)
+	if table == "" || table == info.TableName {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s is stored in %s, but %s uses %s", ErrProtoTableMismatch, desc.FullName(), table, info.Name, info.TableName)
+}
+
+// inFieldMask returns true if the field at path is selected by the field mask,
+// or if it is a message containing selected fields
+func inFieldMask(mask *fieldmaskpb.FieldMask, path string) bool {
+	if mask == nil {
+		return true
+	}
+
+	for _, p := range mask.GetPaths() {
+		if p == "*" || p == path || strings.HasPrefix(path, p+".") || strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FromProto creates a model from a protobuf message.
+// Fields are mapped to columns the same way as in ProtoReflect.
+// Wrappers and optional fields map to sql.Null types or pointers, Timestamp to time.Time,
+// Duration to time.Duration, enums to integers or their names, and repeated fields to slices
+// such as pq.StringArray.
+// Nested messages are flattened, for example spec.image maps to the spec_image column,
+// and messages with the jsonb option are stored as protojson in their column.
+// Fields of unset nested messages are NULL.
+// Fields without a column in the model are ignored, maps return ErrUnsupportedType.
+func FromProto[T any](msg proto.Message) (*T, error) {
+	return FromProtoWithOpts[T](msg, ProtoReflectOptions{})
+}
+
+// FromProtoWithOpts creates a model from a protobuf message using custom options.
+// Only the fields in the field mask of the options are set if a mask is given.
+func FromProtoWithOpts[T any](msg proto.Message, opts ProtoReflectOptions) (*T, error) {
+	x := new(T)
+	m := msg.ProtoReflect()
+	model := reflect.ValueOf(x).Elem()
+	info := Model[T]()
+
+	err := checkProtoTable(m.Descriptor(), info)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range protoFields(m.Descriptor(), info, opts) {
+		fd := field.fd
+		dst := model.Field(field.column.Index)
+
+		// Fields of unset messages are NULL
+		parent := m
+		for _, p := range field.parents {
+			if !parent.Has(p) {
+				parent = nil
+				break
+			}
+			parent = parent.Get(p).Message()
+		}
+
+		var err error
+		switch {
+		case parent == nil:
+			dst.SetZero()
+		case field.json:
+			err = setModelJSON(dst, fd, parent)
+		case fd.IsMap():
+			err = fmt.Errorf("%w: map %s", ErrUnsupportedType, fd.FullName())
+		case fd.IsList():
+			err = setModelList(dst, fd, parent.Get(fd).List())
+		default:
+			var value any
+			value, err = fromProtoValue(fd, parent)
+			if err == nil {
+				err = setModelValue(dst, value)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("converting %s to %s: %w", fd.FullName(), field.column.Field, err)
+		}
+	}
+
+	return x, nil
+}
+
+// ToProto copies a model into a protobuf message.
+// It is the reverse of FromProto, NULL values clear the field.
+// The model can be a struct or a pointer to a struct.
+func ToProto(model any, msg proto.Message) error {
+	return ToProtoWithOpts(model, msg, ProtoReflectOptions{})
+}
+
+// ToProtoWithOpts copies a model into a protobuf message using custom options.
+// Only the fields in the field mask of the options are set if a mask is given.
+func ToProtoWithOpts(model any, msg proto.Message, opts ProtoReflectOptions) error {
+	m := msg.ProtoReflect()
+	src := reflect.Indirect(reflect.ValueOf(model))
+	info := modelOf(src.Type())
+
+	err := checkProtoTable(m.Descriptor(), info)
+	if err != nil {
+		return err
+	}
+
+	fields := protoFields(m.Descriptor(), info, opts)
+	for _, field := range fields {
+		fd := field.fd
+		value := src.Field(field.column.Index)
+
+		parent := m
+		for _, p := range field.parents {
+			parent = parent.Mutable(p).Message()
+		}
+
+		var err error
+		switch {
+		case field.json:
+			err = setProtoJSON(parent, fd, value)
+		case fd.IsMap():
+			err = fmt.Errorf("%w: map %s", ErrUnsupportedType, fd.FullName())
+		case fd.IsList():
+			err = setProtoList(parent, fd, value)
+		default:
+			err = setProtoValue(parent, fd, value)
+		}
+		if err != nil {
+			return fmt.Errorf("converting %s to %s: %w", field.column.Field, fd.FullName(), err)
+		}
+	}
+
+	// Flattened messages without values stay unset
+	for _, field := range fields {
+		clearEmptyParents(m, field.parents)
+	}
+
+	return nil
+}
+
+// clearEmptyParents clears the flattened messages containing a field if they have no fields set, innermost first
+func clearEmptyParents(m protoreflect.Message, parents []protoreflect.FieldDescriptor) {
+	for i := len(parents) - 1; i >= 0; i-- {
+		owner := m
+		for _, p := range parents[:i] {
+			owner = owner.Get(p).Message()
+		}
+
+		if !owner.Has(parents[i]) {
+			continue
+		}
+		empty := true
+		owner.Get(parents[i]).Message().Range(func(protoreflect.FieldDescriptor, protoreflect.Value) bool {
+			empty = false
+			return false
+		})
+		if empty {
+			owner.Clear(parents[i])
+		}
+	}
+}
+
+// setModelJSON sets a model field to the JSON of a message field, or clears it if the message is not set.
+// Messages are stored as protojson, the same way ProtoReflect filters them.
+func setModelJSON(dst reflect.Value, fd protoreflect.FieldDescriptor, m protoreflect.Message) error {
+	if !m.Has(fd) {
+		dst.SetZero()
+		return nil
+	}
+
+	b, err := protojson.Marshal(m.Get(fd).Message().Interface())
+	if err != nil {
+		return err
+	}
+
+	var value any = b
+	if t := dst.Type(); t.Kind() == reflect.String || (t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.String) {
+		value = string(b)
+	}
+
+	return setModelValue(dst, value)
+}
+
+// setProtoJSON sets a message field from the JSON in a model field, NULL values clear the field.
+// Unknown JSON fields are ignored.
+func setProtoJSON(m protoreflect.Message, fd protoreflect.FieldDescriptor, value reflect.Value) error {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			m.Clear(fd)
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if value.Type().Implements(valuerType) {
+		driverValue, err := value.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return err
+		}
+		if driverValue == nil {
+			m.Clear(fd)
+			return nil
+		}
+		value = reflect.ValueOf(driverValue)
+	}
+
+	var b []byte
+	switch {
+	case value.Kind() == reflect.String:
+		b = []byte(value.String())
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8:
+		b = value.Bytes()
+	default:
+		return fmt.Errorf("%w: %s can't hold JSON", ErrProtoTypeMismatch, value.Type())
+	}
+	if len(b) == 0 || string(b) == "null" {
+		m.Clear(fd)
+		return nil
+	}
+
+	msg := m.NewField(fd).Message()
+	err := protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, msg.Interface())
+	if err != nil {
+		return err
+	}
+	m.Set(fd, protoreflect.ValueOfMessage(msg))
+
+	return nil
+}
+
+// fromProtoValue returns the Go value of a singular field, or nil if the field is not set
+func fromProtoValue(fd protoreflect.FieldDescriptor, m protoreflect.Message) (any, error) {
+	if fd.HasPresence() && !m.Has(fd) {
+		return nil, nil
+	}
+
+	return fromProtoScalar(fd, m.Get(fd))
+}
+
+// fromProtoScalar converts a protobuf value to a Go value
+func fromProtoScalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) (any, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return v.Bool(), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return v.Int(), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return v.Uint(), nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float(), nil
+	case protoreflect.StringKind:
+		return v.String(), nil
+	case protoreflect.BytesKind:
+		return v.Bytes(), nil
+	case protoreflect.EnumKind:
+		return protoEnum{fd: fd, number: v.Enum()}, nil
+	case protoreflect.MessageKind:
+		m := v.Message()
+		fields := m.Descriptor().Fields()
+		switch m.Descriptor().FullName() {
+		case "google.protobuf.Timestamp":
+			seconds := m.Get(fields.ByName("seconds")).Int()
+			nanos := m.Get(fields.ByName("nanos")).Int()
+			return time.Unix(seconds, nanos).UTC(), nil
+		case "google.protobuf.Duration":
+			seconds := m.Get(fields.ByName("seconds")).Int()
+			nanos := m.Get(fields.ByName("nanos")).Int()
+			return time.Duration(seconds)*time.Second + time.Duration(nanos), nil
+		}
+		if protoWrappers[m.Descriptor().FullName()] {
+			value := fields.ByName("value")
+			return fromProtoScalar(value, m.Get(value))
+		}
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, m.Descriptor().FullName())
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, fd.Kind())
+}
+
+// protoEnum is an enum value, set as the value name into strings and as the number otherwise
+type protoEnum struct {
+	fd     protoreflect.FieldDescriptor
+	number protoreflect.EnumNumber
+}
+
+// protoWrappers are the wrapper messages, which are converted as their value
+var protoWrappers = map[protoreflect.FullName]bool{
+	"google.protobuf.StringValue": true,
+	"google.protobuf.BytesValue":  true,
+	"google.protobuf.BoolValue":   true,
+	"google.protobuf.DoubleValue": true,
+	"google.protobuf.FloatValue":  true,
+	"google.protobuf.Int32Value":  true,
+	"google.protobuf.Int64Value":  true,
+	"google.protobuf.UInt32Value": true,
+	"google.protobuf.UInt64Value": true,
+}
+
+// setModelList sets a slice field from a repeated field
+func setModelList(dst reflect.Value, fd protoreflect.FieldDescriptor, list protoreflect.List) error {
+	if dst.Kind() != reflect.Slice {
+		return fmt.Errorf("%w: %s can't hold a list", ErrProtoTypeMismatch, dst.Type())
+	}
+
+	slice := reflect.MakeSlice(dst.Type(), list.Len(), list.Len())
+	for i := range list.Len() {
+		value, err := fromProtoScalar(fd, list.Get(i))
+		if err != nil {
+			return err
+		}
+		if err := setModelValue(slice.Index(i), value); err != nil {
+			return err
+		}
+	}
+	dst.Set(slice)
+
+	return nil
+}
+
+// setModelValue sets a model field to a value returned by fromProtoScalar
+func setModelValue(dst reflect.Value, value any) error {
+	if value == nil {
+		dst.SetZero()
+		return nil
+	}
+
+	if dst.Kind() == reflect.Pointer {
+		elem := reflect.New(dst.Type().Elem())
+		if err := setModelValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+
+	if enum, ok := value.(protoEnum); ok {
+		value = int64(enum.number)
+		if dst.Kind() == reflect.String {
+			if enumValue := enum.fd.Enum().Values().ByNumber(enum.number); enumValue != nil {
+				value = string(enumValue.Name())
+			}
+		}
+	}
+
+	if scanner, ok := dst.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+
+	v := reflect.ValueOf(value)
+	if !convertible(v.Type(), dst.Type()) {
+		return fmt.Errorf("%w: %s can't hold %s", ErrProtoTypeMismatch, dst.Type(), v.Type())
+	}
+	if numericKind(v.Kind()) {
+		converted, err := convertNumber(v, dst.Type())
+		if err != nil {
+			return err
+		}
+		dst.Set(converted)
+		return nil
+	}
+	dst.Set(v.Convert(dst.Type()))
+
+	return nil
+}
+
+// convertNumber converts a numeric value to the numeric type to.
+// Returns ErrNumericOverflow if the value doesn't fit, or if a float with a fraction is converted to an integer.
+func convertNumber(v reflect.Value, to reflect.Type) (reflect.Value, error) {
+	target := reflect.Zero(to)
+	overflow := false
+
+	switch {
+	case v.CanInt():
+		x := v.Int()
+		switch {
+		case target.CanInt():
+			overflow = target.OverflowInt(x)
+		case target.CanUint():
+			overflow = x < 0 || target.OverflowUint(uint64(x))
+		}
+	case v.CanUint():
+		x := v.Uint()
+		switch {
+		case target.CanInt():
+			overflow = x > math.MaxInt64 || target.OverflowInt(int64(x))
+		case target.CanUint():
+			overflow = target.OverflowUint(x)
+		}
+	case v.CanFloat():
+		x := v.Float()
+		switch {
+		case target.CanInt():
+			overflow = x != math.Trunc(x) || x < math.MinInt64 || x >= math.MaxInt64 || target.OverflowInt(int64(x))
+		case target.CanUint():
+			overflow = x != math.Trunc(x) || x < 0 || x >= math.MaxUint64 || target.OverflowUint(uint64(x))
+		case target.CanFloat():
+			overflow = target.OverflowFloat(x)
+		}
+	}
+	if overflow {
+		return reflect.Value{}, fmt.Errorf("%w: %v doesn't fit in %s", ErrNumericOverflow, v.Interface(), to)
+	}
+
+	return v.Convert(to), nil
+}
+
+// convertible returns true if values of type from can be converted to type to
+// without changing their meaning, for example int64 to int32 but not int64 to string.
+func convertible(from reflect.Type, to reflect.Type) bool {
+	if !from.ConvertibleTo(to) {
+		return false
+	}
+
+	return numericKind(from.Kind()) && numericKind(to.Kind()) || from.Kind() == to.Kind()
+}
+
+// numericKind returns true for integer and float kinds
+func numericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// setProtoList sets a repeated field from a slice field
+func setProtoList(m protoreflect.Message, fd protoreflect.FieldDescriptor, value reflect.Value) error {
+	value = reflect.Indirect(value)
+	if value.Kind() != reflect.Slice {
+		return fmt.Errorf("%w: %s is not a list", ErrProtoTypeMismatch, value.Type())
+	}
+	if value.Len() == 0 {
+		m.Clear(fd)
+		return nil
+	}
+
+	list := m.NewField(fd).List()
+	newMessage := func() protoreflect.Message {
+		return list.NewElement().Message()
+	}
+	for i := range value.Len() {
+		v, ok, err := toProtoValue(fd, value.Index(i), newMessage)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: lists can't contain NULL", ErrProtoTypeMismatch)
+		}
+		list.Append(v)
+	}
+	m.Set(fd, protoreflect.ValueOfList(list))
+
+	return nil
+}
+
+// setProtoValue sets a singular field, NULL values clear the field
+func setProtoValue(m protoreflect.Message, fd protoreflect.FieldDescriptor, value reflect.Value) error {
+	newMessage := func() protoreflect.Message {
+		return m.NewField(fd).Message()
+	}
+	v, ok, err := toProtoValue(fd, value, newMessage)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		m.Clear(fd)
+		return nil
+	}
+	m.Set(fd, v)
+
+	return nil
+}
+
+// toProtoValue converts a Go value to a protobuf value.
+// newMessage creates the message for message fields.
+// Returns false if the value is NULL.
+func toProtoValue(fd protoreflect.FieldDescriptor, value reflect.Value, newMessage func() protoreflect.Message) (protoreflect.Value, bool, error) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return protoreflect.Value{}, false, nil
+		}
+		value = value.Elem()
+	}
+
+	// sql.Null types return their value or nil
+	if value.Type().Implements(valuerType) {
+		driverValue, err := value.Interface().(driver.Valuer).Value()
+		if err != nil {
+			return protoreflect.Value{}, false, err
+		}
+		if driverValue == nil {
+			return protoreflect.Value{}, false, nil
+		}
+		value = reflect.ValueOf(driverValue)
+	}
+
+	kind := value.Kind()
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if kind == reflect.Bool {
+			return protoreflect.ValueOfBool(value.Bool()), true, nil
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if numericKind(kind) {
+			return toProtoNumber(value, protoreflect.ValueOfInt32)
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if numericKind(kind) {
+			return toProtoNumber(value, protoreflect.ValueOfInt64)
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if numericKind(kind) {
+			return toProtoNumber(value, protoreflect.ValueOfUint32)
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if numericKind(kind) {
+			return toProtoNumber(value, protoreflect.ValueOfUint64)
+		}
+	case protoreflect.FloatKind:
+		if numericKind(kind) {
+			return toProtoNumber(value, protoreflect.ValueOfFloat32)
+		}
+	case protoreflect.DoubleKind:
+		if numericKind(kind) {
+			return toProtoNumber(value, protoreflect.ValueOfFloat64)
+		}
+	case protoreflect.StringKind:
+		if kind == reflect.String {
+			return protoreflect.ValueOfString(value.String()), true, nil
+		}
+	case protoreflect.BytesKind:
+		if kind == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			return protoreflect.ValueOfBytes(value.Bytes()), true, nil
+		}
+	case protoreflect.EnumKind:
+		if kind == reflect.String {
+			enumValue := fd.Enum().Values().ByName(protoreflect.Name(value.String()))
+			if enumValue == nil {
+				return protoreflect.Value{}, false, fmt.Errorf("%w: %s is not a value of %s", ErrProtoTypeMismatch, value.String(), fd.Enum().FullName())
+			}
+			return protoreflect.ValueOfEnum(enumValue.Number()), true, nil
+		}
+		if numericKind(kind) {
+			return toProtoNumber(value, func(x int32) protoreflect.Value {
+				return protoreflect.ValueOfEnum(protoreflect.EnumNumber(x))
+			})
+		}
+	case protoreflect.MessageKind:
+		return toProtoMessage(fd, value, newMessage)
+	}
+
+	return protoreflect.Value{}, false, fmt.Errorf("%w: %s can't hold %s", ErrProtoTypeMismatch, fd.Kind(), value.Type())
+}
+
+// toProtoMessage converts a Go value to a Timestamp, Duration or wrapper message
+func toProtoMessage(fd protoreflect.FieldDescriptor, value reflect.Value, newMessage func() protoreflect.Message) (protoreflect.Value, bool, error) {
+	name := fd.Message().FullName()
+	fields := fd.Message().Fields()
+
+	switch {
+	case name == "google.protobuf.Timestamp" && value.Type() == timeType:
+		t := value.Interface().(time.Time)
+		if t.IsZero() {
+			return protoreflect.Value{}, false, nil
+		}
+
+		msg := newMessage()
+		msg.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(t.Unix()))
+		msg.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(t.Nanosecond())))
+		return protoreflect.ValueOfMessage(msg), true, nil
+	case name == "google.protobuf.Duration" && value.Type() == durationType:
+		d := time.Duration(value.Int())
+
+		msg := newMessage()
+		msg.Set(fields.ByName("seconds"), protoreflect.ValueOfInt64(int64(d/time.Second)))
+		msg.Set(fields.ByName("nanos"), protoreflect.ValueOfInt32(int32(d%time.Second)))
+		return protoreflect.ValueOfMessage(msg), true, nil
+	case protoWrappers[name]:
+		v, ok, err := toProtoValue(fields.ByName("value"), value, nil)
+		if !ok || err != nil {
+			return v, ok, err
+		}
+
+		msg := newMessage()
+		msg.Set(fields.ByName("value"), v)
+		return protoreflect.ValueOfMessage(msg), true, nil
+	case name == "google.protobuf.Timestamp", name == "google.protobuf.Duration":
+		return protoreflect.Value{}, false, fmt.Errorf("%w: %s can't hold %s", ErrProtoTypeMismatch, name, value.Type())
+	}
+
+	return protoreflect.Value{}, false, fmt.Errorf("%w: %s", ErrUnsupportedType, name)
+}
+
+// toProtoNumber converts a numeric value to T and returns it as a protobuf value.
+// Returns ErrNumericOverflow if the value doesn't fit in T.
+func toProtoNumber[T int32 | int64 | uint32 | uint64 | float32 | float64](value reflect.Value, valueOf func(T) protoreflect.Value) (protoreflect.Value, bool, error) {
+	converted, err := convertNumber(value, reflect.TypeOf(T(0)))
+	if err != nil {
+		return protoreflect.Value{}, false, err
+	}
+
+	return valueOf(converted.Interface().(T)), true, nil
+}
+
+// convertTo converts a numeric value to T
+func convertTo[T int32 | int64 | uint32 | uint64 | float32 | float64](value reflect.Value) T {
+	return value.Convert(reflect.TypeOf(T(0))).Interface().(T)
+}
@@ -0,0 +1,302 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	pikatestpb "go.ciq.dev/pika/testproto"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type complete3Model struct {
+	PikaTableName string `pika:"complete_3"`
+
+	ID           int            `db:"id"`
+	Str          string         `db:"str"`
+	NullableInt  sql.NullInt64  `db:"nullable_int"`
+	NullableBool *bool          `db:"nullable_bool"`
+	Bool         bool           `db:"bool"`
+	Timestamp    time.Time      `db:"timestamp"`
+	Status       int            `db:"status"`
+	Strs         pq.StringArray `db:"strs"`
+}
+
+type complete3StringModel struct {
+	Status string         `db:"status"`
+	Str    sql.NullString `db:"str"`
+}
+
+type annotated4Model struct {
+	PikaTableName string `pika:"simple_model_1"`
+
+	ID        int64      `db:"id"`
+	Title     string     `db:"title"`
+	CreatedAt *time.Time `db:"created_at"`
+	Internal  string     `db:"internal"`
+}
+
+func TestFromProto(t *testing.T) {
+	ts := time.Date(2023, 7, 30, 12, 0, 0, 5, time.UTC)
+	msg := &pikatestpb.Complete3{
+		Str:          "hello",
+		NullableInt:  wrapperspb.Int32(5),
+		NullableBool: wrapperspb.Bool(true),
+		Bool:         true,
+		Timestamp:    timestamppb.New(ts),
+		Status:       pikatestpb.Status_STATUS_ERROR,
+		Strs:         []string{"a", "b"},
+	}
+
+	x, err := FromProto[complete3Model](msg)
+	require.Nil(t, err)
+	require.Equal(t, "hello", x.Str)
+	require.Equal(t, sql.NullInt64{Int64: 5, Valid: true}, x.NullableInt)
+	require.NotNil(t, x.NullableBool)
+	require.True(t, *x.NullableBool)
+	require.True(t, x.Bool)
+	require.Equal(t, ts, x.Timestamp)
+	require.Equal(t, 2, x.Status)
+	require.Equal(t, pq.StringArray{"a", "b"}, x.Strs)
+
+	// Unset wrappers are NULL
+	x, err = FromProto[complete3Model](&pikatestpb.Complete3{Str: "hello"})
+	require.Nil(t, err)
+	require.False(t, x.NullableInt.Valid)
+	require.Nil(t, x.NullableBool)
+	require.True(t, x.Timestamp.IsZero())
+	require.Len(t, x.Strs, 0)
+
+	// Enums can be stored as their names
+	y, err := FromProto[complete3StringModel](msg)
+	require.Nil(t, err)
+	require.Equal(t, "STATUS_ERROR", y.Status)
+	require.Equal(t, sql.NullString{String: "hello", Valid: true}, y.Str)
+}
+
+func TestToProto(t *testing.T) {
+	nullableBool := false
+	x := &complete3Model{
+		Str:          "hello",
+		NullableInt:  sql.NullInt64{Int64: 5, Valid: true},
+		NullableBool: &nullableBool,
+		Timestamp:    time.Date(2023, 7, 30, 12, 0, 0, 0, time.UTC),
+		Status:       3,
+		Strs:         pq.StringArray{"a"},
+	}
+
+	msg := &pikatestpb.Complete3{}
+	err := ToProto(x, msg)
+	require.Nil(t, err)
+	require.True(t, proto.Equal(&pikatestpb.Complete3{
+		Str:          "hello",
+		NullableInt:  wrapperspb.Int32(5),
+		NullableBool: wrapperspb.Bool(false),
+		Timestamp:    timestamppb.New(x.Timestamp),
+		Status:       pikatestpb.Status_STATUS_CANCELED,
+		Strs:         []string{"a"},
+	}, msg))
+
+	// Round trip
+	y, err := FromProto[complete3Model](msg)
+	require.Nil(t, err)
+	require.Equal(t, x, y)
+
+	// NULL values clear the field
+	err = ToProto(complete3Model{Str: "world"}, msg)
+	require.Nil(t, err)
+	require.True(t, proto.Equal(&pikatestpb.Complete3{Str: "world"}, msg))
+
+	err = ToProto(complete3StringModel{Status: "STATUS_OK"}, msg)
+	require.Nil(t, err)
+	require.Equal(t, pikatestpb.Status_STATUS_OK, msg.Status)
+
+	err = ToProto(complete3StringModel{Status: "UNKNOWN"}, msg)
+	require.ErrorIs(t, err, ErrProtoTypeMismatch)
+}
+
+func TestProtoFieldMask(t *testing.T) {
+	msg := &pikatestpb.Complete3{
+		Str:    "hello",
+		Bool:   true,
+		Status: pikatestpb.Status_STATUS_OK,
+	}
+	opts := ProtoReflectOptions{
+		FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"str", "status"}},
+	}
+
+	x, err := FromProtoWithOpts[complete3Model](msg, opts)
+	require.Nil(t, err)
+	require.Equal(t, &complete3Model{Str: "hello", Status: 1}, x)
+
+	msg = &pikatestpb.Complete3{Bool: true}
+	err = ToProtoWithOpts(complete3Model{Str: "world", Status: 2}, msg, opts)
+	require.Nil(t, err)
+	require.True(t, proto.Equal(&pikatestpb.Complete3{Str: "world", Bool: true, Status: pikatestpb.Status_STATUS_ERROR}, msg))
+
+	opts.FieldMask.Paths = []string{"*"}
+	x, err = FromProtoWithOpts[complete3Model](msg, opts)
+	require.Nil(t, err)
+	require.True(t, x.Bool)
+}
+
+func TestProtoColumnOption(t *testing.T) {
+	ts := time.Date(2023, 7, 30, 12, 0, 0, 0, time.UTC)
+	msg := &pikatestpb.Annotated4{
+		Id:         1,
+		Title:      "hello",
+		CreateTime: timestamppb.New(ts),
+		Internal:   "secret",
+	}
+
+	x, err := FromProto[annotated4Model](msg)
+	require.Nil(t, err)
+	require.Equal(t, int64(1), x.ID)
+	require.Equal(t, ts, *x.CreatedAt)
+	require.Equal(t, "secret", x.Internal)
+
+	x, err = FromProtoWithOpts[annotated4Model](msg, ProtoReflectOptions{Exclude: []string{"internal"}})
+	require.Nil(t, err)
+	require.Equal(t, "", x.Internal)
+}
+
+func TestProtoNested(t *testing.T) {
+	type nestedModel struct {
+		ID                 int             `db:"id"`
+		Name               string          `db:"name"`
+		SpecContainerImage string          `db:"spec_container_image"`
+		SpecReplicas       int32           `db:"spec_replicas"`
+		Metadata           json.RawMessage `db:"metadata"`
+		ContainerImage     *string         `db:"container_image"`
+	}
+
+	msg := &pikatestpb.Nested5{
+		Name: "web",
+		Spec: &pikatestpb.Spec5{
+			Container: &pikatestpb.Container5{Image: "rocky"},
+			Replicas:  3,
+		},
+		Metadata: &pikatestpb.Metadata5{Status: pikatestpb.Status_STATUS_OK, Deleted: true},
+		Source:   &pikatestpb.Nested5_Container{Container: &pikatestpb.Container5{Image: "alma"}},
+	}
+
+	// Nested messages are flattened, JSONB messages are stored as JSON
+	x, err := FromProto[nestedModel](msg)
+	require.Nil(t, err)
+	require.Equal(t, "web", x.Name)
+	require.Equal(t, "rocky", x.SpecContainerImage)
+	require.Equal(t, int32(3), x.SpecReplicas)
+	require.JSONEq(t, `{"status": "STATUS_OK", "deleted": true}`, string(x.Metadata))
+	require.Equal(t, "alma", *x.ContainerImage)
+
+	ret := &pikatestpb.Nested5{}
+	err = ToProto(x, ret)
+	require.Nil(t, err)
+	require.True(t, proto.Equal(msg, ret), ret.String())
+
+	// Messages without values stay unset
+	x, err = FromProto[nestedModel](&pikatestpb.Nested5{Name: "web"})
+	require.Nil(t, err)
+	require.Equal(t, &nestedModel{Name: "web"}, x)
+
+	ret = &pikatestpb.Nested5{}
+	err = ToProto(x, ret)
+	require.Nil(t, err)
+	require.True(t, proto.Equal(&pikatestpb.Nested5{Name: "web"}, ret), ret.String())
+
+	// Masks and exclusions apply to nested paths
+	x, err = FromProtoWithOpts[nestedModel](msg, ProtoReflectOptions{
+		FieldMask: &fieldmaskpb.FieldMask{Paths: []string{"spec.replicas", "metadata"}},
+		Exclude:   []string{"metadata"},
+	})
+	require.Nil(t, err)
+	require.Equal(t, &nestedModel{SpecReplicas: 3}, x)
+
+	err = ToProto(nestedModel{Metadata: json.RawMessage(`{"unknown": 1}`)}, ret)
+	require.Nil(t, err)
+	require.NotNil(t, ret.Metadata)
+
+	err = ToProto(nestedModel{Metadata: json.RawMessage(`{"deleted": "no"}`)}, ret)
+	require.NotNil(t, err)
+}
+
+func TestProtoTableOption(t *testing.T) {
+	// Annotated4 is stored in simple_model_1
+	x, err := FromProto[simpleModel1](&pikatestpb.Annotated4{Id: 1, Title: "hello"})
+	require.Nil(t, err)
+	require.Equal(t, "hello", x.Title)
+
+	_, err = FromProto[complete3Model](&pikatestpb.Annotated4{})
+	require.ErrorIs(t, err, ErrProtoTableMismatch)
+	require.Equal(t, "protobuf message is stored in another table: Annotated4 is stored in simple_model_1, but complete3Model uses complete_3", err.Error())
+
+	err = ToProto(complete3Model{}, &pikatestpb.Annotated4{})
+	require.ErrorIs(t, err, ErrProtoTableMismatch)
+
+	_, err = updateMaskColumns(Model[complete3Model](), &pikatestpb.Annotated4{}, nil, ProtoReflectOptions{})
+	require.ErrorIs(t, err, ErrProtoTableMismatch)
+
+	// Messages without the option can be used with any model
+	_, err = FromProto[complete3Model](&pikatestpb.Simple1{})
+	require.Nil(t, err)
+}
+
+func TestProtoTypeMismatch(t *testing.T) {
+	type mismatchModel struct {
+		Str int `db:"str"`
+	}
+
+	_, err := FromProto[mismatchModel](&pikatestpb.Complete3{Str: "hello"})
+	require.ErrorIs(t, err, ErrProtoTypeMismatch)
+	require.Equal(t, "converting Complete3.str to Str: protobuf field does not match model field: int can't hold string", err.Error())
+
+	err = ToProto(mismatchModel{Str: 1}, &pikatestpb.Complete3{})
+	require.ErrorIs(t, err, ErrProtoTypeMismatch)
+}
+
+func TestProtoNumericOverflow(t *testing.T) {
+	type overflowModel struct {
+		Sint64  int8    `db:"sint_64"`
+		Fixed64 int64   `db:"fixed_64"`
+		Sint32  uint32  `db:"sint_32"`
+		Float   float64 `db:"float"`
+	}
+
+	// Values that fit are converted
+	x, err := FromProto[overflowModel](&pikatestpb.Kinds6{Sint64: 100, Fixed64: 1 << 62, Sint32: 7})
+	require.Nil(t, err)
+	require.Equal(t, int8(100), x.Sint64)
+	require.Equal(t, int64(1<<62), x.Fixed64)
+	require.Equal(t, uint32(7), x.Sint32)
+
+	_, err = FromProto[overflowModel](&pikatestpb.Kinds6{Sint64: 300})
+	require.ErrorIs(t, err, ErrNumericOverflow)
+	require.Equal(t, "converting Kinds6.sint64 to Sint64: value is out of range: 300 doesn't fit in int8", err.Error())
+
+	_, err = FromProto[overflowModel](&pikatestpb.Kinds6{Fixed64: 1 << 63})
+	require.ErrorIs(t, err, ErrNumericOverflow)
+
+	_, err = FromProto[overflowModel](&pikatestpb.Kinds6{Sint32: -1})
+	require.ErrorIs(t, err, ErrNumericOverflow)
+
+	err = ToProto(overflowModel{Fixed64: -1}, &pikatestpb.Kinds6{})
+	require.ErrorIs(t, err, ErrNumericOverflow)
+
+	err = ToProto(overflowModel{Float: 1e300}, &pikatestpb.Kinds6{})
+	require.ErrorIs(t, err, ErrNumericOverflow)
+
+	msg := &pikatestpb.Kinds6{}
+	err = ToProto(overflowModel{Float: 1.5, Sint32: 7}, msg)
+	require.Nil(t, err)
+	require.Equal(t, float32(1.5), msg.Float)
+	require.Equal(t, int32(7), msg.Sint32)
+}
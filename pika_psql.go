@@ -7,11 +7,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"maps"
 	"reflect"
 	"strings"
 
-	"github.com/gertd/go-pluralize"
-	"github.com/iancoleman/strcase"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
@@ -29,13 +28,15 @@ const (
 
 // Static errors for err113 compliance
 var (
-	ErrTooManyArguments = errors.New("too many arguments (count should be one pointer or none)")
-	ErrPageSizeNegative = errors.New("page size cannot be negative")
-	ErrCountNegative    = errors.New("count cannot be negative")
-	ErrMissingArgument  = errors.New("missing argument")
-	ErrInvalidOperator  = errors.New("invalid operator")
-	ErrInvalidKey       = errors.New("invalid key")
-	ErrBothModelsNil    = errors.New("modelFirst and modelSecond are all nil, this is not allowed")
+	ErrTooManyArguments  = errors.New("too many arguments (count should be one pointer or none)")
+	ErrPageSizeNegative  = errors.New("page size cannot be negative")
+	ErrCountNegative     = errors.New("count cannot be negative")
+	ErrMissingArgument   = errors.New("missing argument")
+	ErrInvalidOperator   = errors.New("invalid operator")
+	ErrInvalidKey        = errors.New("invalid key")
+	ErrBothModelsNil     = errors.New("modelFirst and modelSecond are all nil, this is not allowed")
+	ErrLockNoTransaction = errors.New("row locking requires a transaction")
+	ErrLockMissing       = errors.New("NOWAIT and SKIP LOCKED require FOR UPDATE or FOR SHARE")
 )
 
 // Queryable includes all methods shared by sqlx.DB and sqlx.Tx, allowing
@@ -77,7 +78,9 @@ type basePsql[T any] struct {
 	*PageToken[T]
 	*base
 
-	psql *PostgreSQL
+	psql    *PostgreSQL
+	model   *ModelInfo
+	tracker *Tracker[T]
 }
 
 // CreateOption represents options for database insert operations.
@@ -131,7 +134,7 @@ func (p *PostgreSQL) Commit() error {
 			p.tx = nil
 		}()
 
-		return p.tx.Commit()
+		return queryError(p.tx.Commit())
 	}
 
 	return errors.New("no transaction to commit")
@@ -164,6 +167,12 @@ func (p *PostgreSQL) DB() *sqlx.DB {
 	return p.db
 }
 
+// SetDefaultSchema sets the schema used for tables that are not qualified with a schema.
+// Can be overridden per query set with InSchema.
+func (p *PostgreSQL) SetDefaultSchema(schema string) {
+	p.defaultSchema = schema
+}
+
 // Close closes the database connection.
 func (p *PostgreSQL) Close() error {
 	return p.db.Close()
@@ -180,24 +189,19 @@ func PSQLQuery[T any](p *PostgreSQL) QuerySet[T] {
 		psql:      p,
 	}
 
-	// Initialize metadata once
-	metadata := getPikaMetadata[T]()
-	b.metadata = metadata
-
-	modelName := b.metadata[pikaMetadataModelName]
-	tableName := b.metadata[PikaMetadataTableName]
+	// Metadata is computed once per model type
+	b.model = Model[T]()
+	b.metadata = b.model.metadata
 
 	// Check if we have a table alias for this model
 	// Only applies if the table name is not explicitly set
-	if tableName == "" {
-		if x, ok := b.psql.tableAlias[modelName]; ok {
-			// If so, use it
-			tableName = x
-		} else {
-			// Otherwise, use the pluralized model name
-			tableName = strcase.ToSnake(pluralize.NewClient().Plural(modelName))
+	// Otherwise, the pluralized model name is used
+	if !b.model.explicitTableName {
+		if x, ok := b.psql.tableAlias[b.model.Name]; ok {
+			// The cached metadata is shared, so copy before changing it
+			b.metadata = maps.Clone(b.metadata)
+			b.metadata[PikaMetadataTableName] = x
 		}
-		b.metadata[PikaMetadataTableName] = tableName
 	}
 
 	return b
@@ -285,6 +289,16 @@ func (b *basePsql[T]) Create(ctx context.Context, x *T, options ...CreateOption)
 		return b.err
 	}
 
+	err := b.setTenant(ctx, x)
+	if err != nil {
+		return err
+	}
+
+	err = beforeCreate(ctx, x)
+	if err != nil {
+		return err
+	}
+
 	origIgnoreOrderBy := b.ignoreOrderBy
 	b.ignoreOrderBy = true
 
@@ -292,7 +306,7 @@ func (b *basePsql[T]) Create(ctx context.Context, x *T, options ...CreateOption)
 	b.ignoreOrderBy = origIgnoreOrderBy
 
 	// Execute query
-	err := b.psql.Queryable().GetContext(ctx, x, q, args...)
+	err = b.getContext(ctx, OperationCreate, x, q, args)
 	if err != nil {
 		// ignore no rows in resultset error when ignoreConflict is set to true, this is a normal case
 		if errors.Is(err, sql.ErrNoRows) && (InsertOnConflictionDoNothing&getOption(options...) != 0) {
@@ -301,7 +315,7 @@ func (b *basePsql[T]) Create(ctx context.Context, x *T, options ...CreateOption)
 		return err
 	}
 
-	return nil
+	return afterCreate(ctx, x)
 }
 
 // Update updates a record in the database.
@@ -310,18 +324,35 @@ func (b *basePsql[T]) Update(ctx context.Context, x *T) error {
 		return b.err
 	}
 
+	// Rows can't be moved to another tenant
+	err := b.setTenant(ctx, x)
+	if err != nil {
+		return err
+	}
+
+	err = b.applyTenant(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = beforeUpdate(ctx, x)
+	if err != nil {
+		return err
+	}
+
 	origIgnoreOrderBy := b.ignoreOrderBy
 	b.ignoreOrderBy = true
 	q, args := b.UpdateQuery(x)
 	b.ignoreOrderBy = origIgnoreOrderBy
 
 	// Execute query
-	err := b.psql.Queryable().GetContext(ctx, x, q, args...)
+	err = b.getContext(ctx, OperationUpdate, x, q, args)
 	if err != nil {
 		return err
 	}
+	b.retrack(x)
 
-	return nil
+	return afterUpdate(ctx, x)
 }
 
 // Delete deletes a record from the database.
@@ -330,13 +361,46 @@ func (b *basePsql[T]) Delete(ctx context.Context) error {
 		return b.err
 	}
 
+	// BeforeDelete is called on the rows, so they have to be loaded first
+	var x T
+	if _, ok := any(&x).(BeforeDeleter); ok {
+		rows, err := b.all(ctx, OperationAll)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			err = beforeDelete(ctx, row)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return b.delete(ctx)
+}
+
+// delete deletes the matching rows without calling model hooks
+func (b *basePsql[T]) delete(ctx context.Context) error {
+	err := b.applyTenant(ctx)
+	if err != nil {
+		return err
+	}
+
 	origIgnoreOrderBy := b.ignoreOrderBy
 	b.ignoreOrderBy = true
 	q, args := b.DeleteQuery()
 	b.ignoreOrderBy = origIgnoreOrderBy
 
 	// Execute query
-	_, err := b.psql.Queryable().ExecContext(ctx, q, args...)
+	err = b.runQuery(ctx, OperationDelete, q, args, func(ctx context.Context) (int64, error) {
+		result, err := b.psql.execContext(ctx, q, args)
+		if err != nil {
+			return 0, err
+		}
+
+		return result.RowsAffected()
+	})
 	if err != nil {
 		return err
 	}
@@ -351,24 +415,33 @@ func (b *basePsql[T]) GetOrNil(ctx context.Context) (*T, error) {
 		return nil, b.err
 	}
 
+	err := b.applyTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	q, args := b.GetOrNilQuery()
 	if b.err != nil {
 		return nil, b.err
 	}
 
+	err = b.checkLock()
+	if err != nil {
+		return nil, err
+	}
+
 	// Execute query
-	var x T
+	x, err := b.getOne(ctx, OperationGetOrNil, q, args)
+	if err != nil || x == nil {
+		return nil, err
+	}
 
-	// Send arguments to prepared statement
-	err := b.psql.Queryable().GetContext(ctx, &x, q, args...)
+	err = b.afterFind(ctx, x)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
 		return nil, err
 	}
 
-	return &x, nil
+	return x, nil
 }
 
 // Get returns a single value
@@ -379,18 +452,66 @@ func (b *basePsql[T]) Get(ctx context.Context) (*T, error) {
 		return nil, b.err
 	}
 
+	err := b.applyTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	q, args := b.GetQuery()
 
+	err = b.checkLock()
+	if err != nil {
+		return nil, err
+	}
+
 	// Execute query
-	var x T
+	x, err := b.getOne(ctx, OperationGet, q, args)
+	if err != nil {
+		return nil, err
+	}
+	if x == nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrNotFound, b.model.Name, sql.ErrNoRows)
+	}
+
+	err = b.afterFind(ctx, x)
+	if err != nil {
+		return nil, err
+	}
+
+	return x, nil
+}
+
+// takesFirst returns true if Get and GetOrNil take the first row instead of
+// checking for multiple rows, which is the case with an OrderBy or a lock.
+// For example, a work queue takes the oldest pending job with
+// OrderBy("created_at").ForUpdate().SkipLocked().GetOrNil(ctx).
+func (b *basePsql[T]) takesFirst() bool {
+	return len(b.orderBy) > 0 || b.lock != ""
+}
+
+// getOne runs the query of Get or GetOrNil, returning nil if no row is found.
+// The query is limited to two rows, so multiple rows return ErrMultipleRows,
+// unless the first row is taken.
+func (b *basePsql[T]) getOne(ctx context.Context, operation string, q string, args []any) (*T, error) {
+	var x []*T
 
 	// Send arguments to prepared statement
-	err := b.psql.Queryable().GetContext(ctx, &x, q, args...)
+	err := b.runQuery(ctx, operation, q, args, func(ctx context.Context) (int64, error) {
+		err := b.psql.selectContext(ctx, &x, q, args)
+		return int64(len(x)), err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &x, nil
+	switch len(x) {
+	case 0:
+		return nil, nil
+	case 1:
+		return x[0], nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrMultipleRows, b.model.Name)
 }
 
 // All returns all values
@@ -399,13 +520,49 @@ func (b *basePsql[T]) All(ctx context.Context) ([]*T, error) {
 		return nil, b.err
 	}
 
+	return b.list(ctx, OperationAll)
+}
+
+// list returns all values and calls the model hooks.
+// operation is reported to query hooks and logs.
+func (b *basePsql[T]) list(ctx context.Context, operation string) ([]*T, error) {
+	x, err := b.all(ctx, operation)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range x {
+		err = b.afterFind(ctx, row)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return x, nil
+}
+
+// all returns all values without calling model hooks
+func (b *basePsql[T]) all(ctx context.Context, operation string) ([]*T, error) {
+	err := b.applyTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	q, args := b.AllQuery()
 
+	err = b.checkLock()
+	if err != nil {
+		return nil, err
+	}
+
 	// Execute query
 	var x []*T
 
 	// Send arguments to prepared statement
-	err := b.psql.Queryable().SelectContext(ctx, &x, q, args...)
+	err = b.runQuery(ctx, operation, q, args, func(ctx context.Context) (int64, error) {
+		err := b.psql.selectContext(ctx, &x, q, args)
+		return int64(len(x)), err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -419,6 +576,17 @@ func (b *basePsql[T]) Count(ctx context.Context) (int, error) {
 		return 0, b.err
 	}
 
+	return b.count(ctx, OperationCount)
+}
+
+// count returns the number of values.
+// operation is reported to query hooks and logs.
+func (b *basePsql[T]) count(ctx context.Context, operation string) (int, error) {
+	err := b.applyTenant(ctx)
+	if err != nil {
+		return 0, err
+	}
+
 	// Execute query
 	var x int
 
@@ -426,9 +594,11 @@ func (b *basePsql[T]) Count(ctx context.Context) (int, error) {
 	origIgnoreLimit := b.ignoreLimit
 	origIgnoreOffset := b.ignoreOffset
 	origIgnoreOrderBy := b.ignoreOrderBy
+	origIgnoreLock := b.ignoreLock
 	b.ignoreLimit = true
 	b.ignoreOffset = true
 	b.ignoreOrderBy = true
+	b.ignoreLock = true
 	filterStatement, args := b.queryWithFilters()
 	preSelect := b.psqlSelectList(b.excludeColumns, b.includeColumns, false)
 	// Strip preSelect from filterStatement
@@ -436,13 +606,19 @@ func (b *basePsql[T]) Count(ctx context.Context) (int, error) {
 	b.ignoreLimit = origIgnoreLimit
 	b.ignoreOffset = origIgnoreOffset
 	b.ignoreOrderBy = origIgnoreOrderBy
+	b.ignoreLock = origIgnoreLock
 
 	// Get select query and append filter statement
 	selectQuery := b.psqlCountQuery()
 	q := fmt.Sprintf("%s%s", selectQuery, filterStatement)
-	logger.Debugf("Pika query: %s", q)
 
-	err := b.psql.Queryable().GetContext(ctx, &x, q, args...)
+	// Distinct rows can't be counted with COUNT(*) directly, so count the rows of the
+	// distinct query instead
+	if b.distinct {
+		q = fmt.Sprintf("SELECT COUNT(*) FROM (%s%s) \"pika_count\"", preSelect, filterStatement)
+	}
+
+	err = b.getContext(ctx, operation, &x, q, args)
 	if err != nil {
 		return 0, err
 	}
@@ -450,6 +626,99 @@ func (b *basePsql[T]) Count(ctx context.Context) (int, error) {
 	return x, nil
 }
 
+// Exists returns true if at least one row matches the query
+func (b *basePsql[T]) Exists(ctx context.Context) (bool, error) {
+	if b.err != nil {
+		return false, b.err
+	}
+
+	err := b.applyTenant(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	q, args := b.ExistsQuery()
+	if b.err != nil {
+		return false, b.err
+	}
+
+	// Execute query
+	var x bool
+
+	err = b.getContext(ctx, OperationExists, &x, q, args)
+	if err != nil {
+		return false, err
+	}
+
+	return x, nil
+}
+
+// Distinct removes duplicate rows from the result
+func (b *basePsql[T]) Distinct() QuerySet[T] {
+	if b.err != nil {
+		return b
+	}
+
+	b.setDistinct(nil)
+
+	return b
+}
+
+// DistinctOn keeps only the first row of each set of rows where the given columns are equal
+func (b *basePsql[T]) DistinctOn(columns ...string) QuerySet[T] {
+	if b.err != nil {
+		return b
+	}
+
+	b.setDistinct(columns)
+
+	return b
+}
+
+// ForUpdate locks the selected rows against concurrent updates
+func (b *basePsql[T]) ForUpdate() QuerySet[T] {
+	if b.err != nil {
+		return b
+	}
+
+	b.lock = "FOR UPDATE"
+
+	return b
+}
+
+// ForShare locks the selected rows against concurrent updates, but allows other shared locks
+func (b *basePsql[T]) ForShare() QuerySet[T] {
+	if b.err != nil {
+		return b
+	}
+
+	b.lock = "FOR SHARE"
+
+	return b
+}
+
+// NoWait returns an error instead of waiting for locked rows
+func (b *basePsql[T]) NoWait() QuerySet[T] {
+	if b.err != nil {
+		return b
+	}
+
+	b.lockWait = "NOWAIT"
+
+	return b
+}
+
+// SkipLocked skips rows that are already locked
+func (b *basePsql[T]) SkipLocked() QuerySet[T] {
+	if b.err != nil {
+		return b
+	}
+
+	b.lockWait = "SKIP LOCKED"
+
+	return b
+}
+
 // Limit sets the limit for the query
 func (b *basePsql[T]) Limit(limit int) QuerySet[T] {
 	if b.err != nil {
@@ -501,16 +770,12 @@ func (b *basePsql[T]) ResetOrderBy() QuerySet[T] {
 // CreateQuery returns the query and arguments for Create
 func (b *basePsql[T]) CreateQuery(x *T, options ...CreateOption) (string, []interface{}) {
 	q, args := b.psqlCreateQuery(x, options...)
-	logger.Debugf("Pika query: %s", q)
-
 	return q, args
 }
 
 // UpdateQuery returns the query and arguments for Update
 func (b *basePsql[T]) UpdateQuery(x *T) (string, []interface{}) {
 	q, args := b.psqlUpdateQuery(x)
-	logger.Debugf("Pika query: %s", q)
-
 	return q, args
 }
 
@@ -518,24 +783,35 @@ func (b *basePsql[T]) UpdateQuery(x *T) (string, []interface{}) {
 func (b *basePsql[T]) DeleteQuery() (string, []interface{}) {
 	modelName := b.metadata[pikaMetadataModelName]
 
+	// Locking is only valid for SELECT
+	origIgnoreLock := b.ignoreLock
+	b.ignoreLock = true
 	filterStatement, args := b.filterStatement()
+	b.ignoreLock = origIgnoreLock
 	filterStatement = strings.ReplaceAll(filterStatement, fmt.Sprintf("\"%s\".", modelName), "")
 
-	q := fmt.Sprintf("DELETE FROM \"%s\"", b.metadata[PikaMetadataTableName])
+	q := fmt.Sprintf("DELETE FROM %s", b.tableRef(b.metadata[PikaMetadataTableName]))
 	q += filterStatement
-	logger.Debugf("Pika query: %s", q)
-
 	return q, args
 }
 
 func (b *basePsql[T]) GetOrNilQuery() (string, []interface{}) {
 	b.ignoreLimit = true
-	q, args := b.queryWithFilters()
 
-	// Limit to one
-	q += " LIMIT 1"
+	// Locking clause has to be added after the limit
+	origIgnoreLock := b.ignoreLock
+	b.ignoreLock = true
+	q, args := b.queryWithFilters()
+	b.ignoreLock = origIgnoreLock
 
-	logger.Debugf("Pika query: %s", q)
+	// Limit to two, so multiple rows can be detected,
+	// unless the first row is taken
+	if b.takesFirst() {
+		q += " LIMIT 1"
+	} else {
+		q += " LIMIT 2"
+	}
+	q += b.lockClause()
 
 	return q, args
 }
@@ -549,8 +825,20 @@ func (b *basePsql[T]) GetQuery() (string, []interface{}) {
 // AllQuery returns the query and arguments for All
 func (b *basePsql[T]) AllQuery() (string, []interface{}) {
 	q, args := b.queryWithFilters()
-	logger.Debugf("Pika query: %s", q)
+	return q, args
+}
 
+// ExistsQuery returns the query and arguments for Exists
+func (b *basePsql[T]) ExistsQuery() (string, []interface{}) {
+	origIgnoreOrderBy := b.ignoreOrderBy
+	origIgnoreLock := b.ignoreLock
+	b.ignoreOrderBy = true
+	b.ignoreLock = true
+	q, args := b.queryWithFilters()
+	b.ignoreOrderBy = origIgnoreOrderBy
+	b.ignoreLock = origIgnoreLock
+
+	q = fmt.Sprintf("SELECT EXISTS(%s)", q)
 	return q, args
 }
 
@@ -594,12 +882,16 @@ func (b *basePsql[T]) GetPage(ctx context.Context, paginatable Paginatable, opti
 		b.PageSize = uint(pageSize)
 	}
 
-	qs, err := b.pageToken(b, options)
+	_, err := b.pageToken(b, options)
 	if err != nil {
 		return nil, "", err
 	}
+	b.orderByPrimaryKey()
+	if b.err != nil {
+		return nil, "", b.err
+	}
 
-	result, err := qs.All(ctx)
+	result, err := b.list(ctx, OperationGetPage)
 	if err != nil {
 		return nil, "", err
 	}
@@ -607,7 +899,7 @@ func (b *basePsql[T]) GetPage(ctx context.Context, paginatable Paginatable, opti
 	b.PageToken.Offset += uint(len(result))
 
 	// Get count and check if there are more results
-	count, err := b.Count(ctx)
+	count, err := b.count(ctx, OperationGetPageCount)
 	if err != nil {
 		return nil, "", fmt.Errorf("getting count: %w", err)
 	}
@@ -681,24 +973,54 @@ func (b *basePsql[T]) Include(includes ...string) QuerySet[T] {
 	return b
 }
 
+// InSchema sets the schema for all tables in the query
+func (b *basePsql[T]) InSchema(schema string) QuerySet[T] {
+	if b.err != nil {
+		return b
+	}
+
+	b.schema = schema
+
+	return b
+}
+
 // Return args, used for reflection
 func (b *basePsql[T]) GetArgs() *orderedmap.OrderedMap[string, interface{}] {
 	return b.args
 } // Return current table and module name, used for reflection
 func (b *basePsql[T]) GetModel() (string, string) {
-	var x T
-	modelName := reflect.TypeOf(x).Name()
-	tableName := modelName
-	ref := reflect.ValueOf(x)
-	for i := range ref.NumField() {
-		field := ref.Type().Field(i)
-		if strings.Compare(field.Name, PikaMetadataTableName) == 0 {
-			tableName = field.Tag.Get("pika")
-			break
-		}
+	tableName := b.model.Name
+	if b.model.explicitTableName {
+		tableName = b.model.TableName
+	}
+
+	return tableName, b.model.Name
+}
+
+// inheritSchema sets the schema from a parent query, unless already set
+func (b *basePsql[T]) inheritSchema(schema string) {
+	if b.schema == "" {
+		b.schema = schema
+	}
+}
+
+// tableRef returns the quoted table name.
+// Unless the table name is already qualified, the schema of the query set
+// or the default schema of the connection is used.
+func (b *basePsql[T]) tableRef(tableName string) string {
+	if strings.Contains(tableName, ".") {
+		return quoteTableName(tableName)
 	}
 
-	return tableName, modelName
+	schema := b.schema
+	if schema == "" {
+		schema = b.psql.defaultSchema
+	}
+	if schema == "" {
+		return quoteTableName(tableName)
+	}
+
+	return quoteTableName(schema + "." + tableName)
 }
 
 type subQuery struct {
@@ -706,6 +1028,27 @@ type subQuery struct {
 	args  *orderedmap.OrderedMap[string, interface{}]
 }
 
+// keysetClause returns the comparison for the keyset, if any.
+// A single column is compared directly, multiple columns as a row value.
+func (b *basePsql[T]) keysetClause(mapping map[string]int) string {
+	if len(b.keyset) == 0 {
+		return ""
+	}
+
+	columns := make([]string, 0, len(b.keyset))
+	placeholders := make([]string, 0, len(b.keyset))
+	for _, column := range b.keyset {
+		columns = append(columns, fmt.Sprintf("\"%s\".\"%s\"", b.metadata[pikaMetadataModelName], column))
+		placeholders = append(placeholders, fmt.Sprintf("$%d", mapping[keysetArg(column)]))
+	}
+
+	if len(b.keyset) == 1 {
+		return fmt.Sprintf("%s > %s", columns[0], placeholders[0])
+	}
+
+	return fmt.Sprintf("(%s) > (%s)", strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+}
+
 func (b *basePsql[T]) filterStatement() (string, []any) {
 	q := ""
 
@@ -718,7 +1061,7 @@ func (b *basePsql[T]) filterStatement() (string, []any) {
 	newArgsMap := orderedmap.New[string, interface{}]()
 
 	// Process filters if any
-	if len(b.filters) > 0 {
+	if len(b.filters) > 0 || len(b.scopes) > 0 || len(b.keyset) > 0 {
 		// Map args to numbers
 		// And reverse mapping to easily get the name
 		if b.args.Len() > 0 {
@@ -738,11 +1081,16 @@ func (b *basePsql[T]) filterStatement() (string, []any) {
 				if isTarget(v) {
 					// Retrieve subquery type info
 					tname, mname := getQuerySetInfo(v)
-					b.replaceFields[tname] = &replaceField{
+					b.replaceFields[unqualifiedTableName(tname)] = &replaceField{
 						tableName: tname,
 						modelName: mname,
 					}
 
+					// Subqueries use the same schema as the parent query
+					if sq, ok := v.(interface{ inheritSchema(string) }); ok && b.schema != "" {
+						sq.inheritSchema(b.schema)
+					}
+
 					// Retrieve subquery details
 					query, args := getSubQuery(v)
 					if args.Len() > 0 {
@@ -787,213 +1135,60 @@ func (b *basePsql[T]) filterStatement() (string, []any) {
 		}
 
 		// Process filters
-		q += " WHERE "
-		for _, filter := range b.filters {
-			// If no filters, then open with parenthesis
-			innerQ := "("
-
-			// Else
-			// If not first filter, add AND/OR
-			if !strings.HasSuffix(q, "WHERE ") {
-				innerQ = " AND ("
-				if filter.or {
-					innerQ = " OR ("
-				}
-			}
-
-			// Loop through filter entries
-			for pair := filter.entries.Oldest(); pair != nil; pair = pair.Next() {
-				// vSpace is used to determine if we need to add a space
-				// Required only for IS NULL and IS NOT NULL
-				vSpace := " "
-
-				// Whether or not to switch left-hand side with right-hand side
-				// This is used for IN and NOT IN where we're checking if a single value
-				// is present in the array column value
-				shouldSwitchKV := false
-
-				// kWrapper is whether to wrap the key in a function
-				// Mostly used for ANY and ALL when checking array columns
-				keyWrapper := ""
-
-				k := pair.Key
-				v := pair.Value
-
-				// If argument is set, use it
-				// Only if the value starts with a ":"
-				noWildcard := strings.ReplaceAll(v, "%", "")
-				startWildcard := strings.HasPrefix(v, "%")
-				endWildcard := strings.HasSuffix(v, "%")
-				if strings.HasPrefix(noWildcard, ":") {
-					// Allow a percentage sign to be used as a wildcard
-					// Both prefix and suffix
-					// Ignore it for the purposes of named parameters
-					if _, ok := b.args.Get(noWildcard[1:]); ok {
-						// If mapping found, replace with numbered parameter
-						v = fmt.Sprintf("$%d", mapping[noWildcard[1:]])
-					} else {
-						b.err = fmt.Errorf("%w: %s", ErrMissingArgument, noWildcard)
-						return "", nil
-					}
-				}
-				andOr := "AND"
-				if filter.innerOr {
-					andOr = "OR"
-				}
-
-				operator := "="
-				// If key contains "__", then try to find hint
-				if strings.Contains(k, "__") {
-					parts := strings.Split(k, "__")
-					k = parts[0]
-					op := "__" + parts[1]
-
-					// IN requires the value wrapped in ANY
-					// as go-pika sends the value as a slice
-					if op == HintIn {
-						// If the field type is a StringArray, then switch left-hand side and right-hand side
-						// This is because left-hand side cannot be ANY
-						origV := v
-						// If it's a variable pointing to subquery object
-						if val, ok := subQueryMap[noWildcard[1:]]; ok {
-							v = fmt.Sprintf("IN (%s)", val.query)
-							// We do not need "="
-							op = HintEmpty
-						} else {
-							v = fmt.Sprintf("ANY(%s)", v)
-							if x, ok := b.metadata[k]; ok {
-								if strings.HasPrefix(x, "pq.") && strings.HasSuffix(x, "Array") {
-									v = origV
-									shouldSwitchKV = true
-									keyWrapper = "ANY"
-								}
-							}
-						}
-					}
-
-					// NOT IN requires the value wrapped in ALL
-					// as go-pika sends the value as a slice
-					if op == HintNotIn {
-						// If the field type is a StringArray, then switch left-hand side and right-hand side
-						// This is because left-hand side cannot be ALL
-						origV := v
-						if val, ok := subQueryMap[noWildcard[1:]]; ok {
-							v = fmt.Sprintf("NOT IN (%s)", val.query)
-							op = HintEmpty
-						} else {
-							v = fmt.Sprintf("ALL(%s)", v)
-							if x, ok := b.metadata[k]; ok {
-								if strings.HasPrefix(x, "pq.") && strings.HasSuffix(x, "Array") {
-									v = origV
-									shouldSwitchKV = true
-									keyWrapper = "ALL"
-								}
-							}
-						}
-					}
-
-					// If LIKE or NOT LIKE, then respect wildcards
-					// Also for not case sensitive variants
-					if op == HintLike || op == HintNotLike || op == HintILike || op == HintNotILike {
-						// If a start wildcard was found, then add a prefix
-						if startWildcard {
-							v = "'%' || " + v
-						}
-
-						// If an end wildcard was found, then add a suffix
-						if endWildcard {
-							v = v + " || '%'"
-						}
-					}
-
-					// If IS NULL or IS NOT NULL, then ignore value
-					if op == HintIsNull || op == HintIsNotNull {
-						v = ""
-						vSpace = ""
-					}
-
-					extraHintOp := op
-					if len(parts) > maxHintParts {
-						extraHintOp = "__" + parts[2]
-					}
-
-					// If AND then set andOr to AND regardless of filter.innerOr
-					// We do this by replacing last AND/OR with AND
-					if op == HintAnd || extraHintOp == HintAnd {
-						innerQ = strings.TrimSuffix(innerQ, "AND ")
-						innerQ = strings.TrimSuffix(innerQ, "OR ")
-
-						// Add if it's not start of subexpression
-						if !strings.HasSuffix(innerQ, "(") {
-							innerQ += "AND "
-						}
-					}
-
-					// If OR then set andOr to OR regardless of filter.innerOr
-					if op == HintOr || extraHintOp == HintOr {
-						innerQ = strings.TrimSuffix(innerQ, "AND ")
-						innerQ = strings.TrimSuffix(innerQ, "OR ")
-
-						// Add if it's not start of subexpression
-						if !strings.HasSuffix(innerQ, "(") {
-							innerQ += "OR "
-						}
-					}
-
-					// Check if operator is valid
-					// Only if op is not HintAnd or HintOr
-					if op != HintAnd && op != HintOr {
-						var ok bool
-						operator, ok = operators[op]
-						if !ok {
-							b.err = fmt.Errorf("%w: %s", ErrInvalidOperator, operator)
-							return "", nil
-						}
-					}
-				}
+		where, err := b.filterClause(b.filters, mapping, subQueryMap)
+		if err != nil {
+			b.err = err
+			return "", nil
+		}
 
-				clean := cleanKey(k)
-				finalK := fmt.Sprintf("\"%s\".\"%s\"", b.metadata[pikaMetadataModelName], clean)
-				// If there is a dot in cleanKey, then that means we should assume that
-				// the caller "knows" what they're doing and we should not add the table name
-				if strings.Contains(clean, ".") {
-					// Split by dot, then join with quotes
-					parts := strings.Split(clean, ".")
-					if len(parts) != expectedFieldParts {
-						b.err = fmt.Errorf("%w: %s", ErrInvalidKey, k)
-						return "", nil
+		// Scopes are always combined with AND
+		// Filters containing OR are grouped, so the scopes apply to all of them
+		scopes, err := b.filterClause(b.scopes, mapping, subQueryMap)
+		if err != nil {
+			b.err = err
+			return "", nil
+		}
+		if keyset := b.keysetClause(mapping); keyset != "" {
+			if scopes == "" {
+				scopes = keyset
+			} else {
+				scopes = fmt.Sprintf("%s AND %s", scopes, keyset)
+			}
+		}
+		if scopes != "" {
+			if where == "" {
+				where = scopes
+			} else {
+				for _, filter := range b.filters {
+					if filter.or {
+						where = fmt.Sprintf("(%s)", where)
+						break
 					}
-					finalK = fmt.Sprintf("\"%s\".\"%s\"", parts[0], parts[1])
-				}
-				if keyWrapper != "" {
-					finalK = fmt.Sprintf("%s(%s)", keyWrapper, finalK)
 				}
-
-				if shouldSwitchKV {
-					innerQ += fmt.Sprintf("%s %s %s%s%s ", v, operator, finalK, vSpace, andOr)
-					continue
-				}
-
-				innerQ += fmt.Sprintf("%s %s %s%s%s ", finalK, operator, v, vSpace, andOr)
+				where = fmt.Sprintf("%s AND %s", where, scopes)
 			}
-			// Remove last AND and OR (and first)
-			innerQ = strings.TrimSuffix(innerQ, " AND ")
-			innerQ = strings.TrimSuffix(innerQ, " OR ")
-
-			innerQ += ")"
-
-			// Add to query
-			q += innerQ
 		}
+
+		q += " WHERE " + where
 	}
 
 	// Process order by
 	// If not ignored
 	if !b.ignoreOrderBy {
+		orderBy := b.orderBy
+		if len(orderBy) == 0 {
+			if defaultOrderBy := b.metadata[PikaMetadataDefaultOrderBy]; defaultOrderBy != "" {
+				orderBy = []string{defaultOrderBy}
+			}
+		}
+		if len(b.distinctOn) > 0 {
+			orderBy = distinctOrderBy(b.distinctOn, orderBy)
+		}
+
 		// Proceed if there are order bys
-		if len(b.orderBy) > 0 {
+		if len(orderBy) > 0 {
 			q += " ORDER BY "
-			for _, o := range b.orderBy {
+			for _, o := range orderBy {
 				if strings.HasPrefix(o, "-") {
 					o = fmt.Sprintf("\"%s\".\"%s\" DESC", b.metadata[pikaMetadataModelName], o[1:])
 				} else {
@@ -1003,14 +1198,6 @@ func (b *basePsql[T]) filterStatement() (string, []any) {
 			}
 			// Remove last comma
 			q = strings.TrimSuffix(q, ", ")
-		} else if orderBy := b.metadata[PikaMetadataDefaultOrderBy]; orderBy != "" {
-			q += " ORDER BY "
-			if strings.HasPrefix(orderBy, "-") {
-				orderBy = fmt.Sprintf("\"%s\".\"%s\" DESC", b.metadata[pikaMetadataModelName], orderBy[1:])
-			} else {
-				orderBy = fmt.Sprintf("\"%s\".\"%s\" ASC", b.metadata[pikaMetadataModelName], orderBy)
-			}
-			q += orderBy
 		}
 	}
 
@@ -1022,6 +1209,14 @@ func (b *basePsql[T]) filterStatement() (string, []any) {
 		q += fmt.Sprintf(" OFFSET %d", *b.offset)
 	}
 
+	if !b.ignoreLock {
+		if b.lockWait != "" && b.lock == "" {
+			b.err = ErrLockMissing
+			return "", nil
+		}
+		q += b.lockClause()
+	}
+
 	// Construct argument list
 	// If we have subqueries, we return the newArgsMap instead of b.args, because args are already rearranged
 	if newArgsMap.Len() > 0 {
@@ -1029,7 +1224,6 @@ func (b *basePsql[T]) filterStatement() (string, []any) {
 		for pair := newArgsMap.Oldest(); pair != nil; pair = pair.Next() {
 			args = append(args, pair.Value)
 		}
-		logger.Debugf("Pika args: %v", args)
 		return q, args
 	}
 
@@ -1037,10 +1231,220 @@ func (b *basePsql[T]) filterStatement() (string, []any) {
 	for pair := b.args.Oldest(); pair != nil; pair = pair.Next() {
 		args = append(args, pair.Value)
 	}
-	logger.Debugf("Pika args: %v", args)
 	return q, args
 }
 
+// filterClause renders the given filters, without the WHERE keyword
+func (b *basePsql[T]) filterClause(filters []pikaFiltering, mapping map[string]int, subQueryMap map[string]*subQuery) (string, error) {
+	q := ""
+
+	for _, filter := range filters {
+		// If no filters, then open with parenthesis
+		innerQ := "("
+
+		// Else
+		// If not first filter, add AND/OR
+		if q != "" {
+			innerQ = " AND ("
+			if filter.or {
+				innerQ = " OR ("
+			}
+		}
+
+		// Loop through filter entries
+		for pair := filter.entries.Oldest(); pair != nil; pair = pair.Next() {
+			// vSpace is used to determine if we need to add a space
+			// Required only for IS NULL and IS NOT NULL
+			vSpace := " "
+
+			// Whether or not to switch left-hand side with right-hand side
+			// This is used for IN and NOT IN where we're checking if a single value
+			// is present in the array column value
+			shouldSwitchKV := false
+
+			// kWrapper is whether to wrap the key in a function
+			// Mostly used for ANY and ALL when checking array columns
+			keyWrapper := ""
+
+			k := pair.Key
+			v := pair.Value
+
+			// If argument is set, use it
+			// Only if the value starts with a ":"
+			noWildcard := strings.ReplaceAll(v, "%", "")
+			startWildcard := strings.HasPrefix(v, "%")
+			endWildcard := strings.HasSuffix(v, "%")
+			if strings.HasPrefix(noWildcard, ":") {
+				// Allow a percentage sign to be used as a wildcard
+				// Both prefix and suffix
+				// Ignore it for the purposes of named parameters
+				if _, ok := b.args.Get(noWildcard[1:]); ok {
+					// If mapping found, replace with numbered parameter
+					v = fmt.Sprintf("$%d", mapping[noWildcard[1:]])
+				} else {
+					return "", fmt.Errorf("%w: %s", ErrMissingArgument, noWildcard)
+				}
+			}
+			andOr := "AND"
+			if filter.innerOr {
+				andOr = "OR"
+			}
+
+			operator := "="
+			// If key contains "__", then try to find hint
+			if strings.Contains(k, "__") {
+				parts := strings.Split(k, "__")
+				k = parts[0]
+				op := "__" + parts[1]
+
+				// IN requires the value wrapped in ANY
+				// as go-pika sends the value as a slice
+				if op == HintIn {
+					// If the field type is a StringArray, then switch left-hand side and right-hand side
+					// This is because left-hand side cannot be ANY
+					origV := v
+					// If it's a variable pointing to subquery object
+					if val, ok := subQueryMap[noWildcard[1:]]; ok {
+						v = fmt.Sprintf("IN (%s)", val.query)
+						// We do not need "="
+						op = HintEmpty
+					} else {
+						v = fmt.Sprintf("ANY(%s)", v)
+						if x, ok := b.metadata[k]; ok {
+							if strings.HasPrefix(x, "pq.") && strings.HasSuffix(x, "Array") {
+								v = origV
+								shouldSwitchKV = true
+								keyWrapper = "ANY"
+							}
+						}
+					}
+				}
+
+				// NOT IN requires the value wrapped in ALL
+				// as go-pika sends the value as a slice
+				if op == HintNotIn {
+					// If the field type is a StringArray, then switch left-hand side and right-hand side
+					// This is because left-hand side cannot be ALL
+					origV := v
+					if val, ok := subQueryMap[noWildcard[1:]]; ok {
+						v = fmt.Sprintf("NOT IN (%s)", val.query)
+						op = HintEmpty
+					} else {
+						v = fmt.Sprintf("ALL(%s)", v)
+						if x, ok := b.metadata[k]; ok {
+							if strings.HasPrefix(x, "pq.") && strings.HasSuffix(x, "Array") {
+								v = origV
+								shouldSwitchKV = true
+								keyWrapper = "ALL"
+							}
+						}
+					}
+				}
+
+				// If LIKE or NOT LIKE, then respect wildcards
+				// Also for not case sensitive variants
+				if op == HintLike || op == HintNotLike || op == HintILike || op == HintNotILike {
+					// If a start wildcard was found, then add a prefix
+					if startWildcard {
+						v = "'%' || " + v
+					}
+
+					// If an end wildcard was found, then add a suffix
+					if endWildcard {
+						v = v + " || '%'"
+					}
+				}
+
+				// If IS NULL or IS NOT NULL, then ignore value
+				if op == HintIsNull || op == HintIsNotNull {
+					v = ""
+					vSpace = ""
+				}
+
+				extraHintOp := op
+				if len(parts) > maxHintParts {
+					extraHintOp = "__" + parts[2]
+				}
+
+				// If AND then set andOr to AND regardless of filter.innerOr
+				// We do this by replacing last AND/OR with AND
+				if op == HintAnd || extraHintOp == HintAnd {
+					innerQ = strings.TrimSuffix(innerQ, "AND ")
+					innerQ = strings.TrimSuffix(innerQ, "OR ")
+
+					// Add if it's not start of subexpression
+					if !strings.HasSuffix(innerQ, "(") {
+						innerQ += "AND "
+					}
+				}
+
+				// If OR then set andOr to OR regardless of filter.innerOr
+				if op == HintOr || extraHintOp == HintOr {
+					innerQ = strings.TrimSuffix(innerQ, "AND ")
+					innerQ = strings.TrimSuffix(innerQ, "OR ")
+
+					// Add if it's not start of subexpression
+					if !strings.HasSuffix(innerQ, "(") {
+						innerQ += "OR "
+					}
+				}
+
+				// Check if operator is valid
+				// Only if op is not HintAnd or HintOr
+				if op != HintAnd && op != HintOr {
+					var ok bool
+					operator, ok = operators[op]
+					if !ok {
+						return "", fmt.Errorf("%w: %s", ErrInvalidOperator, operator)
+					}
+				}
+			}
+
+			// A key in the form of column->path->key filters by a JSON path of a JSONB column
+			// and may end in a cast, for example column->key::numeric
+			clean, jsonPath, _ := strings.Cut(cleanKey(k), "->")
+			jsonPath, cast, _ := strings.Cut(jsonPath, "::")
+			finalK := fmt.Sprintf("\"%s\".\"%s\"", b.metadata[pikaMetadataModelName], clean)
+			// If there is a dot in cleanKey, then that means we should assume that
+			// the caller "knows" what they're doing and we should not add the table name
+			if strings.Contains(clean, ".") {
+				// Split by dot, then join with quotes
+				parts := strings.Split(clean, ".")
+				if len(parts) != expectedFieldParts {
+					return "", fmt.Errorf("%w: %s", ErrInvalidKey, k)
+				}
+				finalK = fmt.Sprintf("\"%s\".\"%s\"", parts[0], parts[1])
+			}
+			if jsonPath != "" {
+				finalK = jsonPathRef(finalK, strings.Split(jsonPath, "->"))
+				if cast != "" {
+					finalK = fmt.Sprintf("(%s)::%s", finalK, cast)
+				}
+			}
+			if keyWrapper != "" {
+				finalK = fmt.Sprintf("%s(%s)", keyWrapper, finalK)
+			}
+
+			if shouldSwitchKV {
+				innerQ += fmt.Sprintf("%s %s %s%s%s ", v, operator, finalK, vSpace, andOr)
+				continue
+			}
+
+			innerQ += fmt.Sprintf("%s %s %s%s%s ", finalK, operator, v, vSpace, andOr)
+		}
+		// Remove last AND and OR (and first)
+		innerQ = strings.TrimSuffix(innerQ, " AND ")
+		innerQ = strings.TrimSuffix(innerQ, " OR ")
+
+		innerQ += ")"
+
+		// Add to query
+		q += innerQ
+	}
+
+	return q, nil
+}
+
 func (b *basePsql[T]) queryWithFilters() (string, []interface{}) {
 	// Need to process filter first
 	filterStatement, args := b.filterStatement()
@@ -1051,7 +1455,7 @@ func (b *basePsql[T]) queryWithFilters() (string, []interface{}) {
 		queries := []string{q}
 		for _, join := range b.joins {
 			// It'll be the form of `join_type table2_name model2_name ON model1_name.key = model2_name.key`
-			joinQ := fmt.Sprintf("%s \"%s\" \"%s\" ON \"%s\".\"%s\" = \"%s\".\"%s\"", join.joinType, join.second.tableName, join.second.modelName, join.first.modelName, join.first.key, join.second.modelName, join.second.key)
+			joinQ := fmt.Sprintf("%s %s \"%s\" ON \"%s\".\"%s\" = \"%s\".\"%s\"", join.joinType, b.tableRef(join.second.tableName), join.second.modelName, join.first.modelName, join.first.key, join.second.modelName, join.second.key)
 			queries = append(queries, joinQ)
 		}
 		q = strings.Join(queries, " ")
@@ -1062,11 +1466,6 @@ func (b *basePsql[T]) queryWithFilters() (string, []interface{}) {
 	return q, args
 }
 
-type column struct {
-	db   string
-	pika string
-}
-
 func (b *basePsql[T]) psqlSelectList(excludeColumns []string, includeColumns []string, onlyCols bool) string {
 	// If nil, create empty slice
 	if excludeColumns == nil {
@@ -1075,86 +1474,77 @@ func (b *basePsql[T]) psqlSelectList(excludeColumns []string, includeColumns []s
 	if includeColumns == nil {
 		includeColumns = make([]string, 0)
 	}
+	if b.includeOrderBy && len(includeColumns) > 0 {
+		includeColumns = b.orderByColumns(includeColumns)
+	}
 
 	// Get info from metadata
 	tableName := b.metadata[PikaMetadataTableName]
 	modelName := b.metadata[pikaMetadataModelName]
 
-	// Create dummy instance of T
-	var x T
-
-	// Reflect value to fetch fields and tags
-	ref := reflect.ValueOf(x)
-
-	columns := make([]column, 0, ref.NumField())
-
-	// Iterate through fields to get tags
-	for i := range ref.NumField() {
-		field := ref.Type().Field(i)
-		tag := field.Tag.Get("db")
-		// By default, pikaTag = tag
-		pikaTag := tag
-		if field.Tag.Get("pika") != "" {
-			// If pikaTag is set, then it'll be that set value
-			pikaTag = field.Tag.Get("pika")
-		}
-
-		// Ignore empty or "-" tags
-		if tag == "" || tag == "-" {
-			continue
-		}
+	columns := make([]*ColumnInfo, 0, len(b.model.Columns))
 
+	// Iterate through the model columns
+	for _, column := range b.model.Columns {
 		// Check if we have a dedicated include list
-		// and if the current tag is not in it
+		// and if the current column is not in it
 		// then skip it
-		if len(includeColumns) > 0 && !contains(includeColumns, pikaTag) {
+		if len(includeColumns) > 0 && !contains(includeColumns, column.PikaName) {
 			continue
 		}
 
 		// Check if we have a dedicated exclude list
-		// and if the current tag is in it
+		// and if the current column is in it
 		// then skip it
-		if len(excludeColumns) > 0 && contains(excludeColumns, pikaTag) {
+		if len(excludeColumns) > 0 && contains(excludeColumns, column.PikaName) {
 			continue
 		}
 
-		columns = append(columns, column{
-			db:   tag,
-			pika: pikaTag,
-		})
+		columns = append(columns, column)
 	}
 
 	// Default from str
-	fromStrs := []string{fmt.Sprintf("FROM \"%s\" \"%s\"", tableName, modelName)}
+	fromStrs := []string{fmt.Sprintf("FROM %s \"%s\"", b.tableRef(tableName), modelName)}
 
 	// Prefix each column with the model name
 	// to avoid conflicts
 	var selectColumns []string
 	for _, column := range columns {
-		if column.pika != "" {
-			values := strings.SplitN(column.pika, ".", expectedFieldParts)
+		if column.PikaName != "" {
+			values := strings.SplitN(column.PikaName, ".", expectedFieldParts)
 			if len(values) == expectedFieldParts {
 				if val, ok := b.replaceFields[values[0]]; ok {
 					// Need to replace fields from other tables with associated model prefixs
 					// These fields are defined in the current model, but their values are from other tables
-					selectColumns = append(selectColumns, fmt.Sprintf("\"%s\".\"%s\"", val.modelName, column.db))
+					selectColumns = append(selectColumns, fmt.Sprintf("\"%s\".\"%s\"", val.modelName, column.Name))
 					// If table and model names do NOT exist in joins, we need to add them to from str separately
 					// Otherwise, models definitions are missing in the generated query
 					if !b.checkJoins(val.tableName, val.modelName) {
-						fromStrs = append(fromStrs, fmt.Sprintf("\"%s\" \"%s\"", val.tableName, val.modelName))
+						fromStrs = append(fromStrs, fmt.Sprintf("%s \"%s\"", b.tableRef(val.tableName), val.modelName))
 					}
 					continue
 				}
 			}
 		}
-		selectColumns = append(selectColumns, fmt.Sprintf("\"%s\".\"%s\"", modelName, column.db))
+		selectColumns = append(selectColumns, fmt.Sprintf("\"%s\".\"%s\"", modelName, column.Name))
 	}
 
 	if onlyCols {
 		return strings.Join(selectColumns, ", ")
 	}
 
-	selectStr := "SELECT " + strings.Join(selectColumns, ", ")
+	selectStr := "SELECT "
+	if b.distinct {
+		selectStr += "DISTINCT "
+		if len(b.distinctOn) > 0 {
+			distinctColumns := make([]string, 0, len(b.distinctOn))
+			for _, col := range b.distinctOn {
+				distinctColumns = append(distinctColumns, fmt.Sprintf("\"%s\".\"%s\"", modelName, col))
+			}
+			selectStr += fmt.Sprintf("ON (%s) ", strings.Join(distinctColumns, ", "))
+		}
+	}
+	selectStr += strings.Join(selectColumns, ", ")
 
 	q := fmt.Sprintf("%s %s", selectStr, strings.Join(fromStrs, ","))
 	return q
@@ -1168,7 +1558,7 @@ func (b *basePsql[T]) psqlCountQuery() string {
 	tableName := b.metadata[PikaMetadataTableName]
 	modelName := b.metadata[pikaMetadataModelName]
 
-	fromStr := fmt.Sprintf("FROM \"%s\" \"%s\"", tableName, modelName)
+	fromStr := fmt.Sprintf("FROM %s \"%s\"", b.tableRef(tableName), modelName)
 
 	selectStr := "SELECT COUNT(*)"
 
@@ -1181,44 +1571,10 @@ func (b *basePsql[T]) psqlCreateQuery(value *T, options ...CreateOption) (string
 	tableName := b.metadata[PikaMetadataTableName]
 	modelName := b.metadata[pikaMetadataModelName]
 
-	// Reflect value to fetch fields and tags
-	ref := reflect.ValueOf(value)
-
-	columns := make([]string, 0, ref.Elem().NumField())
-	values := make([]string, 0, ref.Elem().NumField())
-
-	// Iterate through fields to get tags
-	xi := 0
-	for i := range ref.Elem().NumField() {
-		field := ref.Elem().Type().Field(i)
-		tag := field.Tag.Get("db")
-		// Ignore "-" tags (or empty tags)
-		if tag == "" || tag == "-" {
-			continue
-		}
-
-		// Ignore empty or "-" tags
-		tagSplit := strings.Split(field.Tag.Get("pika"), ",")
-		skipCol := false
-		for _, t := range tagSplit {
-			// If tag has "omitempty" and the value is empty
-			// then skip it
-			if t == "omitempty" {
-				fieldValue := ref.Elem().Field(i)
-				if reflect.DeepEqual(fieldValue.Interface(), reflect.Zero(fieldValue.Type()).Interface()) {
-					skipCol = true
-					break
-				}
-			}
-		}
-		if skipCol {
-			continue
-		}
-
-		colName := fmt.Sprintf("\"%s\"", tag)
-		columns = append(columns, colName)
-		values = append(values, fmt.Sprintf("$%d", xi+1))
-		xi++
+	columns, args := b.columnValues(value, false)
+	values := make([]string, 0, len(columns))
+	for i := range columns {
+		values = append(values, fmt.Sprintf("$%d", i+1))
 	}
 
 	columnStr := strings.Join(columns, ", ")
@@ -1233,21 +1589,7 @@ func (b *basePsql[T]) psqlCreateQuery(value *T, options ...CreateOption) (string
 	if InsertOnConflictionDoNothing&getOption(options...) != 0 {
 		conflict = " ON CONFLICT DO NOTHING"
 	}
-	q := fmt.Sprintf("INSERT INTO \"%s\" (%s) VALUES (%s)%s RETURNING %s", tableName, columnStr, valueStr, conflict, selectList)
-
-	// Convert value to arguments
-	args := make([]interface{}, 0, ref.Elem().NumField())
-	for i := range ref.Elem().NumField() {
-		field := ref.Elem().Type().Field(i)
-		tag := fmt.Sprintf("\"%s\"", field.Tag.Get("db"))
-
-		if !contains(columns, tag) {
-			continue
-		}
-
-		fieldElem := ref.Elem().Field(i)
-		args = append(args, fieldElem.Interface())
-	}
+	q := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)%s RETURNING %s", b.tableRef(tableName), columnStr, valueStr, conflict, selectList)
 
 	return q, args
 }
@@ -1257,53 +1599,16 @@ func (b *basePsql[T]) psqlUpdateQuery(value *T) (string, []any) {
 	tableName := b.metadata[PikaMetadataTableName]
 	modelName := b.metadata[pikaMetadataModelName]
 
-	// Reflect value to fetch fields and tags
-	ref := reflect.ValueOf(value)
-
-	columns := make([]string, 0, ref.Elem().NumField())
-	// values := make([]string, 0, ref.Elem().NumField())
-
-	// Iterate through fields to get tags
-	xi := 0
-	for i := range ref.Elem().NumField() {
-		field := ref.Elem().Type().Field(i)
-		tag := field.Tag.Get("db")
-		// Ignore "-" tags (or empty tags)
-		if tag == "" || tag == "-" {
-			continue
-		}
-
-		// Skip ID field
-		if tag == "id" {
-			continue
-		}
-
-		// Ignore empty or "-" tags
-		tagSplit := strings.Split(field.Tag.Get("pika"), ",")
-		skipCol := false
-		for _, t := range tagSplit {
-			// If tag has "omitempty" and the value is empty
-			// then skip it
-			if t == "omitempty" {
-				fieldValue := ref.Elem().Field(i)
-				if reflect.DeepEqual(fieldValue.Interface(), reflect.Zero(fieldValue.Type()).Interface()) {
-					skipCol = true
-					break
-				}
-			}
-		}
-		if skipCol {
-			continue
-		}
-
-		colName := fmt.Sprintf("\"%s\"", tag)
-		columns = append(columns, colName)
-		// values = append(values, fmt.Sprintf("$%d", xi+1))
-		xi++
-	}
+	// Primary key columns are never updated
+	columns, values := b.columnValues(value, true)
 
+	// Locking is only valid for SELECT
+	origIgnoreLock := b.ignoreLock
+	b.ignoreLock = true
 	filterStatement, args := b.filterStatement()
-	if filterStatement == "" {
+	b.ignoreLock = origIgnoreLock
+	// The tenant scope alone is not enough, as all rows of the tenant would be updated
+	if filterStatement == "" || len(b.filters) == 0 {
 		b.err = errors.New("No filter statement found")
 		return "", nil
 	}
@@ -1312,7 +1617,7 @@ func (b *basePsql[T]) psqlUpdateQuery(value *T) (string, []any) {
 	// Remove the model name prefix from the select list
 	// since we are inserting into the table
 	selectList = strings.ReplaceAll(selectList, fmt.Sprintf("\"%s\".", modelName), "")
-	q := fmt.Sprintf("UPDATE \"%s\" SET ", tableName)
+	q := fmt.Sprintf("UPDATE %s SET ", b.tableRef(tableName))
 
 	// Add columns to update
 	for i, col := range columns {
@@ -1327,19 +1632,67 @@ func (b *basePsql[T]) psqlUpdateQuery(value *T) (string, []any) {
 	q += fmt.Sprintf("%s RETURNING %s", filterStatement, selectList)
 
 	// Convert value to arguments
-	for i := range ref.Elem().NumField() {
-		field := ref.Elem().Type().Field(i)
-		tag := fmt.Sprintf("\"%s\"", field.Tag.Get("db"))
+	args = append(args, values...)
+
+	return q, args
+}
 
-		if !contains(columns, tag) {
+// columnValues returns the quoted columns and values of value for insert and update.
+// Columns with "omitempty" in the pika tag are skipped if the value is empty.
+// Primary key columns are skipped if skipPK is set.
+// If update columns are set, only those are returned, including empty values.
+func (b *basePsql[T]) columnValues(value *T, skipPK bool) ([]string, []any) {
+	elem := reflect.ValueOf(value).Elem()
+
+	columns := make([]string, 0, len(b.model.Columns))
+	values := make([]any, 0, len(b.model.Columns))
+	for _, column := range b.model.Columns {
+		if skipPK && column.PrimaryKey {
 			continue
 		}
 
-		fieldElem := ref.Elem().Field(i)
-		args = append(args, fieldElem.Interface())
+		fieldValue := elem.Field(column.Index)
+		if len(b.updateColumns) > 0 {
+			if !contains(b.updateColumns, column.Name) {
+				continue
+			}
+		} else if column.OmitEmpty && fieldValue.IsZero() {
+			continue
+		}
+
+		columns = append(columns, fmt.Sprintf("\"%s\"", column.Name))
+		values = append(values, fieldValue.Interface())
 	}
 
-	return q, args
+	return columns, values
+}
+
+// lockClause returns the row locking clause, prefixed with a space.
+// Only the rows of the queried model are locked if there are joins.
+func (b *basePsql[T]) lockClause() string {
+	if b.lock == "" {
+		return ""
+	}
+
+	q := " " + b.lock
+	if len(b.joins) > 0 {
+		q += fmt.Sprintf(" OF \"%s\"", b.metadata[pikaMetadataModelName])
+	}
+	if b.lockWait != "" {
+		q += " " + b.lockWait
+	}
+
+	return q
+}
+
+// checkLock returns an error if rows are locked outside of a transaction,
+// as the locks would be released immediately.
+func (b *basePsql[T]) checkLock() error {
+	if b.lock != "" && b.psql.tx == nil {
+		return ErrLockNoTransaction
+	}
+
+	return nil
 }
 
 // Check whether given table and module names are inside join array
@@ -1393,11 +1746,11 @@ func (b *basePsql[T]) commonJoin(joinType string, modelFirst, modelSecond interf
 		},
 	})
 
-	b.replaceFields[tnFirst] = &replaceField{
+	b.replaceFields[unqualifiedTableName(tnFirst)] = &replaceField{
 		tableName: tnFirst,
 		modelName: mnFirst,
 	}
-	b.replaceFields[tnSecond] = &replaceField{
+	b.replaceFields[unqualifiedTableName(tnSecond)] = &replaceField{
 		tableName: tnSecond,
 		modelName: mnSecond,
 	}
@@ -6,7 +6,6 @@ package pika
 import (
 	"context"
 	"fmt"
-	"reflect"
 
 	"github.com/pkg/errors"
 )
@@ -16,25 +15,6 @@ var (
 	ErrIDNotFound = errors.New("id not found")
 )
 
-func (b *basePsql[T]) findID(x *T) any {
-	elem := reflect.ValueOf(x).Elem()
-
-	// Check if ID is a field
-	idField := elem.FieldByName("ID")
-	if idField.IsValid() {
-		return idField.Interface()
-	}
-
-	// Also check for Id field
-	idField = elem.FieldByName("Id")
-	if idField.IsValid() {
-		return idField.Interface()
-	}
-
-	// Return nil if ID is not a field
-	return nil
-}
-
 func (b *basePsql[T]) F(keyval ...any) QuerySet[T] {
 	args := NewArgs()
 	var queries []string
@@ -44,23 +24,34 @@ func (b *basePsql[T]) F(keyval ...any) QuerySet[T] {
 		queries = append(queries, filter)
 	}
 
-	logger.Debugf("F: %s", queries)
+	b.psql.logDebug(context.Background(), "Pika F", "queries", queries)
 
 	return b.Args(args).Filter(queries...)
 }
 
 func (b *basePsql[T]) D(ctx context.Context, x *T) error {
-	id := b.findID(x)
-	if id == nil {
-		return ErrIDNotFound
+	pk, err := b.primaryKeyValues(x)
+	if err != nil {
+		return err
 	}
 
-	qs := b.F("id", id)
-	return qs.Delete(ctx)
+	err = beforeDelete(ctx, x)
+	if err != nil {
+		return err
+	}
+
+	// The hook was called on x, so skip loading the row
+	_, err = b.filterPrimaryKey(pk)
+	if err != nil {
+		return err
+	}
+	return b.delete(ctx)
 }
 
 func (b *basePsql[T]) Transaction(ctx context.Context) (QuerySet[T], error) {
 	ts := NewPostgreSQLFromDB(b.psql.DB())
+	// Share table aliases and connection settings
+	ts.connBase = b.psql.connBase
 	err := ts.Begin(ctx)
 	if err != nil {
 		return nil, err
@@ -70,11 +61,14 @@ func (b *basePsql[T]) Transaction(ctx context.Context) (QuerySet[T], error) {
 }
 
 func (b *basePsql[T]) U(ctx context.Context, x *T) error {
-	id := b.findID(x)
-	if id == nil {
-		return ErrIDNotFound
+	pk, err := b.primaryKeyValues(x)
+	if err != nil {
+		return err
 	}
 
-	qs := b.F("id", id)
+	qs, err := b.filterPrimaryKey(pk)
+	if err != nil {
+		return err
+	}
 	return qs.Update(ctx, x)
 }
@@ -0,0 +1,245 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// Static errors for err113 compliance
+var (
+	ErrFetchSizeTooSmall = errors.New("fetch size cannot be less than 1")
+	ErrChunkSizeTooSmall = errors.New("chunk size cannot be less than 1")
+)
+
+// cursorCounter is used to generate unique cursor names
+var cursorCounter atomic.Uint64
+
+// Iter streams all values, scanning one row at a time
+func (b *basePsql[T]) Iter(ctx context.Context) func(yield func(*T, error) bool) {
+	return func(yield func(*T, error) bool) {
+		if b.err != nil {
+			yield(nil, b.err)
+			return
+		}
+
+		err := b.applyTenant(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		q, args := b.AllQuery()
+		if b.err != nil {
+			yield(nil, b.err)
+			return
+		}
+
+		err = b.checkLock()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		// Execute query
+		// The hook is reported once the rows are exhausted or closed, without the time spent in yield
+		err = b.runTimedQuery(ctx, OperationIter, q, args, func(ctx context.Context, timer *queryTimer) (int64, error) {
+			var count int64
+			err := b.psql.queryRows(ctx, q, args, func(rows *sqlx.Rows) error {
+				var err error
+				count, _, err = b.scanRows(ctx, rows, timedYield(timer, yield))
+				return err
+			})
+			return count, err
+		})
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// IterCursor streams all values using a server-side cursor
+func (b *basePsql[T]) IterCursor(ctx context.Context, fetchSize int) func(yield func(*T, error) bool) {
+	return func(yield func(*T, error) bool) {
+		if b.err != nil {
+			yield(nil, b.err)
+			return
+		}
+
+		if fetchSize < 1 {
+			yield(nil, ErrFetchSizeTooSmall)
+			return
+		}
+
+		err := b.applyTenant(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		q, args := b.AllQuery()
+		if b.err != nil {
+			yield(nil, b.err)
+			return
+		}
+
+		err = b.checkLock()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		// Cursors only live inside a transaction
+		// If there is no active transaction, use a read-only one for the cursor
+		tx := b.psql.tx
+		if tx == nil {
+			tx, err = b.psql.DB().BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			defer func() {
+				_ = tx.Rollback()
+			}()
+		}
+
+		name := fmt.Sprintf("pika_cursor_%d", cursorCounter.Add(1))
+		declare := fmt.Sprintf("DECLARE \"%s\" NO SCROLL CURSOR FOR %s", name, q)
+
+		err = b.runTimedQuery(ctx, OperationIterCursor, declare, args, func(ctx context.Context, timer *queryTimer) (int64, error) {
+			return b.fetchCursor(ctx, tx, name, declare, args, fetchSize, timedYield(timer, yield))
+		})
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// fetchCursor declares the cursor and passes the rows to yield, fetching fetchSize rows at a time.
+// Returns the number of rows fetched.
+func (b *basePsql[T]) fetchCursor(ctx context.Context, tx *sqlx.Tx, name string, declare string, args []any, fetchSize int, yield func(*T, error) bool) (int64, error) {
+	_, err := tx.ExecContext(ctx, declare, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_, _ = tx.ExecContext(ctx, fmt.Sprintf("CLOSE \"%s\"", name))
+	}()
+
+	total := int64(0)
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM \"%s\"", fetchSize, name)
+	for {
+		rows, err := tx.QueryxContext(ctx, fetch)
+		if err != nil {
+			return total, err
+		}
+
+		count, more, err := b.scanRows(ctx, rows, yield)
+		_ = rows.Close()
+		total += count
+		if err != nil {
+			return total, err
+		}
+
+		// Stop if the caller stopped or the cursor is exhausted
+		if !more || count < int64(fetchSize) {
+			return total, nil
+		}
+	}
+}
+
+// Chunked calls fn with chunks of values, paging by the primary key
+func (b *basePsql[T]) Chunked(ctx context.Context, size int, fn func([]*T) error) error {
+	if b.err != nil {
+		return b.err
+	}
+
+	if size < 1 {
+		return ErrChunkSizeTooSmall
+	}
+
+	pk, err := b.primaryKey()
+	if err != nil {
+		return err
+	}
+
+	// Keyset pagination requires a stable order on the key
+	// Offset is ignored as the key is used to skip rows instead
+	// The query set is restored afterwards, so it can be reused
+	origIgnoreOffset := b.ignoreOffset
+	origOrderBy := b.orderBy
+	origLimit := b.limit
+	defer func() {
+		b.ignoreOffset = origIgnoreOffset
+		b.orderBy = origOrderBy
+		b.limit = origLimit
+		b.keyset = nil
+		for _, column := range pk {
+			b.args.Delete(keysetArg(column))
+		}
+	}()
+	b.ignoreOffset = true
+	b.setOrderBy(pk, true)
+	b.setLimit(size)
+
+	for {
+		result, err := b.All(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(result) > 0 {
+			err = fn(result)
+			if err != nil {
+				return err
+			}
+		}
+
+		// A short chunk means there are no more rows
+		if len(result) < size {
+			return nil
+		}
+
+		last, err := b.primaryKeyValues(result[len(result)-1])
+		if err != nil {
+			return err
+		}
+
+		// Only continue after the last row of the previous chunk
+		// Composite keys are compared as a row value, matching the order by
+		b.setKeyset(pk, last)
+	}
+}
+
+// scanRows scans each row into a new value and passes it to yield.
+// Returns the number of rows scanned, and false if yield asked to stop.
+// Errors are returned instead of passed to yield.
+func (b *basePsql[T]) scanRows(ctx context.Context, rows *sqlx.Rows, yield func(*T, error) bool) (int64, bool, error) {
+	count := int64(0)
+	for rows.Next() {
+		var x T
+		err := rows.StructScan(&x)
+		if err != nil {
+			return count, false, err
+		}
+
+		err = b.afterFind(ctx, &x)
+		if err != nil {
+			return count, false, err
+		}
+
+		count++
+		if !yield(&x, nil) {
+			return count, false, nil
+		}
+	}
+
+	return count, true, rows.Err()
+}
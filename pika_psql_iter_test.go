@@ -0,0 +1,166 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIter(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	var ids []int
+	Q[simpleModel1](psql).OrderBy("id").Iter(context.Background())(func(x *simpleModel1, err error) bool {
+		require.Nil(t, err)
+		ids = append(ids, x.ID)
+		return true
+	})
+	require.Equal(t, []int{1, 2, 3}, ids)
+}
+
+func TestIterStop(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	var ids []int
+	Q[simpleModel1](psql).OrderBy("id").Iter(context.Background())(func(x *simpleModel1, err error) bool {
+		require.Nil(t, err)
+		ids = append(ids, x.ID)
+		return len(ids) < 2
+	})
+	require.Equal(t, []int{1, 2}, ids)
+}
+
+func TestIterError(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	var errs []error
+	Q[simpleModel1](psql).Filter("id=:id").Iter(context.Background())(func(x *simpleModel1, err error) bool {
+		require.Nil(t, x)
+		errs = append(errs, err)
+		return true
+	})
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], ErrMissingArgument)
+}
+
+func TestIterCursor(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	var ids []int
+	Q[simpleModel1](psql).OrderBy("id").IterCursor(context.Background(), 2)(func(x *simpleModel1, err error) bool {
+		require.Nil(t, err)
+		ids = append(ids, x.ID)
+		return true
+	})
+	require.Equal(t, []int{1, 2, 3}, ids)
+}
+
+func TestIterCursorTransaction(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	err := psql.Begin(context.Background())
+	require.Nil(t, err)
+	defer psql.Rollback()
+
+	args := NewArgs()
+	args.Set("id", 1)
+
+	var ids []int
+	Q[simpleModel1](psql).Filter("id__gt=:id").Args(args).OrderBy("-id").IterCursor(context.Background(), 1)(func(x *simpleModel1, err error) bool {
+		require.Nil(t, err)
+		ids = append(ids, x.ID)
+		return true
+	})
+	require.Equal(t, []int{3, 2}, ids)
+}
+
+func TestChunked(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	var chunks [][]int
+	err := Q[simpleModel1](psql).OrderBy("-id").Chunked(context.Background(), 2, func(x []*simpleModel1) error {
+		var ids []int
+		for _, e := range x {
+			ids = append(ids, e.ID)
+		}
+		chunks = append(chunks, ids)
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, [][]int{{1, 2}, {3}}, chunks)
+}
+
+func TestChunkedTwice(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	qs := Q[simpleModel1](psql).OrderBy("-id").Limit(5)
+	for i := 0; i < 2; i++ {
+		var chunks [][]int
+		err := qs.Chunked(context.Background(), 2, func(x []*simpleModel1) error {
+			var ids []int
+			for _, e := range x {
+				ids = append(ids, e.ID)
+			}
+			chunks = append(chunks, ids)
+			return nil
+		})
+		require.Nil(t, err)
+		require.Equal(t, [][]int{{1, 2}, {3}}, chunks)
+	}
+
+	// The order, limit and filters are restored after Chunked
+	query, args := qs.AllQuery()
+	require.Equal(t, `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" ORDER BY "simpleModel1"."id" DESC LIMIT 5`, query)
+	require.Empty(t, args)
+}
+
+func TestChunkedFilterOr(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	args := NewArgs()
+	args.Set("id", 1)
+	args.Set("id2", 3)
+
+	// The chunk key must apply to both sides of the OR
+	var ids []int
+	err := Q[simpleModel1](psql).Filter("id=:id").FilterOr("id=:id2").Args(args).Chunked(context.Background(), 1, func(x []*simpleModel1) error {
+		for _, e := range x {
+			ids = append(ids, e.ID)
+		}
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, []int{1, 3}, ids)
+}
+
+func TestChunkedError(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	errStop := errors.New("stop")
+	calls := 0
+	err := Q[simpleModel1](psql).Chunked(context.Background(), 1, func(x []*simpleModel1) error {
+		calls++
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, 1, calls)
+
+	err = Q[simpleModel1](psql).Chunked(context.Background(), 0, func(x []*simpleModel1) error {
+		return nil
+	})
+	require.ErrorIs(t, err, ErrChunkSizeTooSmall)
+}
@@ -98,6 +98,13 @@ type joinModelAnotherForeign struct {
 	Product       string `db:"product"`
 }
 
+type schemaModel struct {
+	PikaTableName string `pika:"pika_tenant.schema_model"`
+
+	ID   int    `db:"id" pika:"omitempty"`
+	Name string `db:"name"`
+}
+
 func newPsql(t *testing.T) *PostgreSQL {
 	dbName := "postgres"
 	port := 45111
@@ -214,6 +221,14 @@ func createComplexJoinModel(t *testing.T, psql *PostgreSQL) {
 	psql.db.MustExec(`INSERT INTO join_model_another_foreign VALUES ($1, $2, $3)`, int32(1), int32(1), "product")
 }
 
+func createTestSchema(t *testing.T, psql *PostgreSQL) {
+	psql.db.MustExec("DROP SCHEMA IF EXISTS pika_tenant CASCADE")
+	psql.db.MustExec("CREATE SCHEMA pika_tenant")
+	psql.db.MustExec("CREATE TABLE pika_tenant.simple_model_1 (id SERIAL PRIMARY KEY, title TEXT, description TEXT)")
+	psql.db.MustExec("CREATE TABLE pika_tenant.schema_model (id SERIAL PRIMARY KEY, name TEXT)")
+	psql.db.MustExec("INSERT INTO pika_tenant.simple_model_1 (id, title, description) VALUES (1, 'Tenant', 'Tenant')")
+}
+
 func getMockBasePsql(t *testing.T) *basePsql[simpleModel1] {
 	return PSQLQuery[simpleModel1](newPsql(t)).(*basePsql[simpleModel1])
 }
@@ -272,7 +287,7 @@ func TestPsqlSelectListExcludeIncludeColumn(t *testing.T) {
 func TestNoExplicitTableNamePlural(t *testing.T) {
 	qs := newPsqlQuery[noExplicitTableName](t)
 
-	expectedQuery := `SELECT "noExplicitTableName"."id" FROM "no_explicit_table_names" "noExplicitTableName" LIMIT 1`
+	expectedQuery := `SELECT "noExplicitTableName"."id" FROM "no_explicit_table_names" "noExplicitTableName" LIMIT 2`
 	actualQuery, _ := qs.GetQuery()
 	require.Equal(t, expectedQuery, actualQuery)
 }
@@ -283,7 +298,7 @@ func TestNoExplicitTableNameAlias(t *testing.T) {
 
 	qs := PSQLQuery[noExplicitTableName](psql)
 
-	expectedQuery := `SELECT "noExplicitTableName"."id" FROM "alias_table" "noExplicitTableName" LIMIT 1`
+	expectedQuery := `SELECT "noExplicitTableName"."id" FROM "alias_table" "noExplicitTableName" LIMIT 2`
 	actualQuery, _ := qs.GetQuery()
 	require.Equal(t, expectedQuery, actualQuery)
 }
@@ -297,7 +312,7 @@ func TestGetOrNil(t *testing.T) {
 
 	qs = qs.Filter("id=:id").Args(args)
 
-	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) LIMIT 1`
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) LIMIT 2`
 	expectedArgs := []interface{}{1}
 	actualQuery, actualArgs := qs.GetOrNilQuery()
 	require.Equal(t, expectedQuery, actualQuery)
@@ -320,7 +335,7 @@ func TestGetOrNilNotFound(t *testing.T) {
 	args.Set("id", 999)
 	qs = qs.Filter("id=:id").Args(args)
 
-	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) LIMIT 1`
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) LIMIT 2`
 	expectedArgs := []interface{}{999}
 	actualQuery, actualArgs := qs.GetOrNilQuery()
 	require.Equal(t, expectedQuery, actualQuery)
@@ -343,19 +358,76 @@ func TestGetOrNilOrFilter(t *testing.T) {
 		FilterOr("id=:id2").
 		Args(args)
 
-	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) OR ("simpleModel1"."id" = $2) LIMIT 1`
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) OR ("simpleModel1"."id" = $2) LIMIT 2`
 	expectedArgs := []interface{}{1, 2}
 	actualQuery, actualArgs := qs.GetOrNilQuery()
 	require.Equal(t, expectedQuery, actualQuery)
 	require.Equal(t, expectedArgs, actualArgs)
 
 	ret, err := qs.GetOrNil(context.Background())
+	require.ErrorIs(t, err, ErrMultipleRows)
+	require.Nil(t, ret)
+
+	ret, err = qs.Get(context.Background())
+	require.ErrorIs(t, err, ErrMultipleRows)
+	require.Nil(t, ret)
+
+	// With an OrderBy the first row is taken
+	qs = qs.OrderBy("-id")
+
+	expectedQuery = `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) OR ("simpleModel1"."id" = $2) ORDER BY "simpleModel1"."id" DESC LIMIT 1`
+	actualQuery, _ = qs.GetOrNilQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	ret, err = qs.GetOrNil(context.Background())
 	require.Nil(t, err)
 	require.NotNil(t, ret)
+	require.Equal(t, 2, ret.ID)
+}
 
-	require.Equal(t, 1, ret.ID)
-	require.Equal(t, "Test", ret.Title)
-	require.Equal(t, "Test", ret.Description)
+func TestGetOrNilSkipLocked(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	// Two pending jobs, each worker dequeues the first one that isn't locked
+	args := NewArgs()
+	args.Set("id", 1)
+	dequeue := func(psql *PostgreSQL) QuerySet[simpleModel1] {
+		return Q[simpleModel1](psql).Filter("id__gt=:id").Args(args).OrderBy("id").ForUpdate().SkipLocked()
+	}
+
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" > $1) ORDER BY "simpleModel1"."id" ASC LIMIT 1 FOR UPDATE SKIP LOCKED`
+	actualQuery, _ := dequeue(psql).GetOrNilQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	err := psql.Begin(context.Background())
+	require.Nil(t, err)
+	defer psql.Rollback()
+
+	ret, err := dequeue(psql).GetOrNil(context.Background())
+	require.Nil(t, err)
+	require.NotNil(t, ret)
+	require.Equal(t, 2, ret.ID)
+
+	psql2 := newPsql(t)
+	err = psql2.Begin(context.Background())
+	require.Nil(t, err)
+	defer psql2.Rollback()
+
+	ret, err = dequeue(psql2).GetOrNil(context.Background())
+	require.Nil(t, err)
+	require.NotNil(t, ret)
+	require.Equal(t, 3, ret.ID)
+
+	// No jobs left
+	psql3 := newPsql(t)
+	err = psql3.Begin(context.Background())
+	require.Nil(t, err)
+	defer psql3.Rollback()
+
+	ret, err = dequeue(psql3).GetOrNil(context.Background())
+	require.Nil(t, err)
+	require.Nil(t, ret)
 }
 
 func TestGet(t *testing.T) {
@@ -366,7 +438,7 @@ func TestGet(t *testing.T) {
 	args.Set("id", 1)
 	qs = qs.Filter("id=:id").Args(args)
 
-	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) LIMIT 1`
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) LIMIT 2`
 	expectedArgs := []interface{}{1}
 	actualQuery, actualArgs := qs.GetQuery()
 	require.Equal(t, expectedQuery, actualQuery)
@@ -388,7 +460,7 @@ func TestGetNotFound(t *testing.T) {
 	args.Set("id", 999)
 	qs = qs.Filter("id=:id").Args(args)
 
-	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) LIMIT 1`
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) LIMIT 2`
 	expectedArgs := []interface{}{999}
 	actualQuery, actualArgs := qs.GetQuery()
 	require.Equal(t, expectedQuery, actualQuery)
@@ -396,7 +468,9 @@ func TestGetNotFound(t *testing.T) {
 
 	ret, err := qs.Get(context.Background())
 	require.NotNil(t, err)
-	require.EqualError(t, err, "sql: no rows in result set")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	require.EqualError(t, err, "not found: simpleModel1: sql: no rows in result set")
 	require.Nil(t, ret)
 }
 
@@ -590,7 +664,7 @@ func TestGetOrNilGeneric(t *testing.T) {
 	args.Set("id", 1)
 	qs = qs.Filter("id=:id").Args(args)
 
-	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) LIMIT 1`
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) LIMIT 2`
 	expectedArgs := []interface{}{1}
 	actualQuery, actualArgs := qs.GetOrNilQuery()
 	require.Equal(t, expectedQuery, actualQuery)
@@ -1754,3 +1828,243 @@ func TestGetPageCount(t *testing.T) {
 	require.NotEmpty(t, nt)
 	require.Equal(t, 3, count)
 }
+
+func TestExists(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	args := NewArgs()
+	args.Set("title", "Test2")
+	qs := Q[simpleModel1](psql).Filter("title=:title").Args(args).OrderBy("id")
+
+	expectedQuery := `SELECT EXISTS(SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."title" = $1))`
+	expectedArgs := []interface{}{"Test2"}
+	actualQuery, actualArgs := qs.ExistsQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+	require.Equal(t, expectedArgs, actualArgs)
+
+	exists, err := qs.Exists(context.Background())
+	require.Nil(t, err)
+	require.True(t, exists)
+
+	args = NewArgs()
+	args.Set("title", "NotFound")
+	exists, err = Q[simpleModel1](psql).Filter("title=:title").Args(args).Exists(context.Background())
+	require.Nil(t, err)
+	require.False(t, exists)
+}
+
+func TestDistinct(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries3(t, psql)
+
+	qs := Q[simpleModel3](psql).Include("non_nullable").Distinct().ResetOrderBy().OrderBy("non_nullable")
+
+	expectedQuery := `SELECT DISTINCT "simpleModel3"."non_nullable" FROM "simple_model_3" "simpleModel3" ORDER BY "simpleModel3"."non_nullable" ASC`
+	actualQuery, _ := qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	ret, err := qs.All(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 2, len(ret))
+	require.Equal(t, "String", ret[0].NonNullable)
+
+	count, err := Q[simpleModel3](psql).Include("non_nullable").Distinct().Count(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 2, count)
+}
+
+func TestDistinctOn(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries3(t, psql)
+
+	qs := Q[simpleModel3](psql).DistinctOn("non_nullable").OrderBy("-id", "-non_nullable")
+
+	expectedQuery := `SELECT DISTINCT ON ("simpleModel3"."non_nullable") "simpleModel3"."id", "simpleModel3"."num", "simpleModel3"."non_nullable", "simpleModel3"."nullable" FROM "simple_model_3" "simpleModel3" ORDER BY "simpleModel3"."non_nullable" DESC, "simpleModel3"."id" DESC`
+	actualQuery, _ := qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	ret, err := qs.All(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 2, len(ret))
+	require.Equal(t, 1, ret[0].ID)
+	require.Equal(t, 3, ret[1].ID)
+
+	// Default order by is kept after the distinct columns
+	expectedQuery = `SELECT DISTINCT ON ("simpleModel3"."non_nullable") "simpleModel3"."id", "simpleModel3"."num", "simpleModel3"."non_nullable", "simpleModel3"."nullable" FROM "simple_model_3" "simpleModel3" ORDER BY "simpleModel3"."non_nullable" ASC, "simpleModel3"."id" ASC`
+	actualQuery, _ = Q[simpleModel3](psql).DistinctOn("non_nullable").AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	count, err := Q[simpleModel3](psql).DistinctOn("non_nullable").Count(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 2, count)
+}
+
+func TestForUpdate(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	args := NewArgs()
+	args.Set("id", 0)
+	qs := Q[simpleModel1](psql).Filter("id__gt=:id").Args(args).OrderBy("id").Limit(2).ForUpdate().SkipLocked()
+
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" > $1) ORDER BY "simpleModel1"."id" ASC LIMIT 2 FOR UPDATE SKIP LOCKED`
+	expectedArgs := []interface{}{0}
+	actualQuery, actualArgs := qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+	require.Equal(t, expectedArgs, actualArgs)
+
+	// Locking outside of a transaction is not allowed
+	_, err := qs.All(context.Background())
+	require.ErrorIs(t, err, ErrLockNoTransaction)
+
+	// Lock the first two rows in one transaction
+	err = psql.Begin(context.Background())
+	require.Nil(t, err)
+	defer psql.Rollback()
+
+	ret, err := qs.All(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 2, len(ret))
+	require.Equal(t, 1, ret[0].ID)
+	require.Equal(t, 2, ret[1].ID)
+
+	// Another transaction skips the locked rows
+	psql2 := newPsql(t)
+	err = psql2.Begin(context.Background())
+	require.Nil(t, err)
+	defer psql2.Rollback()
+
+	ret, err = Q[simpleModel1](psql2).OrderBy("id").ForUpdate().SkipLocked().All(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 1, len(ret))
+	require.Equal(t, 3, ret[0].ID)
+
+	// Or fails immediately with NOWAIT
+	_, err = Q[simpleModel1](psql2).OrderBy("id").ForUpdate().NoWait().All(context.Background())
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "could not obtain lock")
+}
+
+func TestForShareQueries(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "simple_model_1" "simpleModel1" LIMIT 1 FOR SHARE NOWAIT`
+	actualQuery, _ := Q[simpleModel1](psql).ForShare().NoWait().GetQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	// Only the main model is locked with joins
+	expectedQuery = `SELECT "joinSimpleModelMain"."id", "joinSimpleModelMain"."name", "joinModelForeign"."id" FROM "join_simple_model_main" "joinSimpleModelMain" INNER JOIN "join_model_foreign" "joinModelForeign" ON "joinSimpleModelMain"."id" = "joinModelForeign"."foreign_key" FOR UPDATE OF "joinSimpleModelMain"`
+	actualQuery, _ = Q[joinSimpleModelMain](psql).InnerJoin(nil, joinModelForeign{}, "id", "foreign_key").ForUpdate().AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	// Updates and deletes never lock
+	args := NewArgs()
+	args.Set("id", 1)
+	expectedQuery = `DELETE FROM "simple_model_1" WHERE ("id" = $1)`
+	actualQuery, _ = Q[simpleModel1](psql).Filter("id=:id").Args(args).ForUpdate().DeleteQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	// Lock modifiers require a lock
+	_, err := Q[simpleModel1](psql).SkipLocked().Count(context.Background())
+	require.Nil(t, err)
+	_, err = Q[simpleModel1](psql).SkipLocked().All(context.Background())
+	require.ErrorIs(t, err, ErrLockMissing)
+}
+
+func TestInSchema(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	createTestSchema(t, psql)
+
+	args := NewArgs()
+	args.Set("id", 1)
+	qs := Q[simpleModel1](psql).InSchema("pika_tenant").Filter("id=:id").Args(args)
+
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "pika_tenant"."simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" = $1) LIMIT 2`
+	actualQuery, _ := qs.GetQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	ret, err := qs.Get(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, "Tenant", ret.Title)
+
+	count, err := Q[simpleModel1](psql).InSchema("pika_tenant").Count(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 1, count)
+
+	// Without a schema, the default table is used
+	count, err = Q[simpleModel1](psql).Count(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 3, count)
+
+	expectedQuery = `UPDATE "pika_tenant"."simple_model_1" SET "title" = $2, "description" = $3 WHERE ("id" = $1) RETURNING "id", "title", "description"`
+	actualQuery, _ = Q[simpleModel1](psql).InSchema("pika_tenant").Filter("id=:id").Args(args).UpdateQuery(&simpleModel1{ID: 1, Title: "Updated"})
+	require.Equal(t, expectedQuery, actualQuery)
+
+	// Joined tables use the same schema
+	expectedQuery = `SELECT "joinSimpleModelMain"."id", "joinSimpleModelMain"."name", "joinModelForeign"."id" FROM "pika_tenant"."join_simple_model_main" "joinSimpleModelMain" INNER JOIN "pika_tenant"."join_model_foreign" "joinModelForeign" ON "joinSimpleModelMain"."id" = "joinModelForeign"."foreign_key"`
+	actualQuery, _ = Q[joinSimpleModelMain](psql).InSchema("pika_tenant").InnerJoin(nil, joinModelForeign{}, "id", "foreign_key").AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	expectedQuery = `DELETE FROM "pika_tenant"."simple_model_1" WHERE ("id" = $1)`
+	actualQuery, _ = Q[simpleModel1](psql).InSchema("pika_tenant").Filter("id=:id").Args(args).DeleteQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+}
+
+func TestDefaultSchema(t *testing.T) {
+	psql := newPsql(t)
+	createTestSchema(t, psql)
+	psql.SetDefaultSchema("pika_tenant")
+
+	expectedQuery := `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "pika_tenant"."simple_model_1" "simpleModel1"`
+	actualQuery, _ := Q[simpleModel1](psql).AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	ret, err := Q[simpleModel1](psql).All(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 1, len(ret))
+	require.Equal(t, "Tenant", ret[0].Title)
+
+	// InSchema overrides the default schema
+	expectedQuery = `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "public"."simple_model_1" "simpleModel1"`
+	actualQuery, _ = Q[simpleModel1](psql).InSchema("public").AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	// Subqueries inherit the schema of the parent query
+	subQs := Q[simpleModel1](psql).Include("id")
+	args := NewArgs()
+	args.Set("ids", subQs)
+	expectedQuery = `SELECT "simpleModel1"."id", "simpleModel1"."title", "simpleModel1"."description" FROM "other"."simple_model_1" "simpleModel1" WHERE ("simpleModel1"."id" IN (SELECT "simpleModel1"."id" FROM "other"."simple_model_1" "simpleModel1"))`
+	actualQuery, _ = Q[simpleModel1](psql).InSchema("other").Filter("id__in=:ids").Args(args).AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+}
+
+func TestSchemaQualifiedTableName(t *testing.T) {
+	psql := newPsql(t)
+	createTestSchema(t, psql)
+	qs := Q[schemaModel](psql)
+
+	entry := schemaModel{
+		Name: "test",
+	}
+
+	expectedQuery := `INSERT INTO "pika_tenant"."schema_model" ("name") VALUES ($1) RETURNING "id", "name"`
+	actualQuery, _ := qs.CreateQuery(&entry)
+	require.Equal(t, expectedQuery, actualQuery)
+
+	err := qs.Create(context.Background(), &entry)
+	require.Nil(t, err)
+	require.Equal(t, 1, entry.ID)
+
+	// Qualified table names are not affected by InSchema
+	expectedQuery = `SELECT "schemaModel"."id", "schemaModel"."name" FROM "pika_tenant"."schema_model" "schemaModel"`
+	actualQuery, _ = Q[schemaModel](psql).InSchema("other").AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+
+	ret, err := Q[schemaModel](psql).All(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 1, len(ret))
+	require.Equal(t, "test", ret[0].Name)
+}
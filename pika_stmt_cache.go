@@ -0,0 +1,315 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// Static errors for err113 compliance
+var (
+	ErrStatementCacheSizeTooSmall = errors.New("statement cache size cannot be less than 1")
+)
+
+// cachedPlanError is returned by PostgreSQL if a prepared statement is used
+// after the result type of the query changed, e.g. after a migration
+const cachedPlanError = "cached plan must not change result type"
+
+// StatementCacheStats contains the metrics of the statement cache
+type StatementCacheStats struct {
+	// Hits is the number of queries that used a cached statement
+	Hits uint64
+	// Misses is the number of queries that prepared a new statement
+	Misses uint64
+	// Evictions is the number of statements closed to make room for new ones
+	Evictions uint64
+	// Invalidations is the number of statements closed after the result type changed
+	Invalidations uint64
+	// Size is the number of cached statements
+	Size int
+}
+
+// HitRate returns the ratio of queries that used a cached statement
+func (s StatementCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total)
+}
+
+// statementCache is a bounded LRU cache of prepared statements, keyed by query
+type statementCache struct {
+	mu      sync.Mutex
+	size    int
+	lru     *list.List
+	entries map[string]*list.Element
+	stats   StatementCacheStats
+	// closed is set once the cache is disabled, statements prepared afterwards are not cached
+	closed bool
+}
+
+type cachedStatement struct {
+	query   string
+	stmt    *sqlx.Stmt
+	refs    int
+	evicted bool
+}
+
+func newStatementCache(size int) *statementCache {
+	return &statementCache{
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// EnableStatementCache prepares generated queries and caches up to size statements.
+// Statements are prepared on the database, queries in a transaction are not cached,
+// as preparing them on the database would need a second connection.
+func (p *PostgreSQL) EnableStatementCache(size int) error {
+	if size < 1 {
+		return ErrStatementCacheSizeTooSmall
+	}
+
+	if old := p.stmtCache.Swap(newStatementCache(size)); old != nil {
+		old.clear()
+	}
+
+	return nil
+}
+
+// DisableStatementCache closes all cached statements and disables the statement cache.
+// Statements in use by running queries are closed once the queries are done.
+func (p *PostgreSQL) DisableStatementCache() {
+	if old := p.stmtCache.Swap(nil); old != nil {
+		old.clear()
+	}
+}
+
+// StatementCacheStats returns the metrics of the statement cache
+func (p *PostgreSQL) StatementCacheStats() StatementCacheStats {
+	cache := p.statementCache()
+	if cache == nil {
+		return StatementCacheStats{}
+	}
+
+	return cache.getStats()
+}
+
+func (c *statementCache) getStats() StatementCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.lru.Len()
+
+	return stats
+}
+
+// acquire returns the statement for the query, preparing it if not cached.
+// The statement must be released after use.
+func (c *statementCache) acquire(ctx context.Context, db *sqlx.DB, query string) (*cachedStatement, error) {
+	c.mu.Lock()
+	if elem, ok := c.entries[query]; ok {
+		c.lru.MoveToFront(elem)
+		s := elem.Value.(*cachedStatement)
+		s.refs++
+		c.stats.Hits++
+		c.mu.Unlock()
+
+		return s, nil
+	}
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	// Prepare without holding the lock
+	stmt, err := db.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another query may have prepared the same statement in the meantime
+	if elem, ok := c.entries[query]; ok {
+		_ = stmt.Close()
+		c.lru.MoveToFront(elem)
+		s := elem.Value.(*cachedStatement)
+		s.refs++
+
+		return s, nil
+	}
+
+	s := &cachedStatement{
+		query: query,
+		stmt:  stmt,
+		refs:  1,
+	}
+
+	// The cache was disabled while preparing, close the statement after use
+	if c.closed {
+		s.evicted = true
+		return s, nil
+	}
+
+	c.entries[query] = c.lru.PushFront(s)
+
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+
+	return s, nil
+}
+
+// release marks the statement as no longer in use
+func (c *statementCache) release(s *cachedStatement) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s.refs--
+	if s.evicted && s.refs == 0 {
+		_ = s.stmt.Close()
+	}
+}
+
+// invalidate removes the statement from the cache
+func (c *statementCache) invalidate(s *cachedStatement) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[s.query]; ok && elem.Value == s {
+		c.remove(elem)
+		c.stats.Invalidations++
+	}
+}
+
+// clear removes all statements from the cache and closes it
+func (c *statementCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	for c.lru.Len() > 0 {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove removes an element from the cache, closing the statement once it's no longer in use.
+// Must be called with the lock held.
+func (c *statementCache) remove(elem *list.Element) {
+	s := c.lru.Remove(elem).(*cachedStatement)
+	delete(c.entries, s.query)
+
+	s.evicted = true
+	if s.refs == 0 {
+		_ = s.stmt.Close()
+	}
+}
+
+// statementCache returns the statement cache, or nil if it is disabled or a transaction is active
+func (p *PostgreSQL) statementCache() *statementCache {
+	if p.tx != nil {
+		return nil
+	}
+
+	return p.stmtCache.Load()
+}
+
+// withStatement calls fn with a cached statement for the query.
+// Statements invalidated by a change of the result type are prepared again and the query is retried once.
+func (p *PostgreSQL) withStatement(ctx context.Context, cache *statementCache, query string, fn func(stmt *sqlx.Stmt) error) error {
+	retry := true
+
+	for {
+		s, err := cache.acquire(ctx, p.db, query)
+		if err != nil {
+			return err
+		}
+
+		err = fn(s.stmt)
+		cache.release(s)
+		if err != nil && strings.Contains(err.Error(), cachedPlanError) {
+			cache.invalidate(s)
+			if retry {
+				retry = false
+				continue
+			}
+		}
+
+		return err
+	}
+}
+
+// getContext runs GetContext, using the statement cache if enabled
+func (p *PostgreSQL) getContext(ctx context.Context, dest any, query string, args []any) error {
+	cache := p.statementCache()
+	if cache == nil {
+		return p.Queryable().GetContext(ctx, dest, query, args...)
+	}
+
+	return p.withStatement(ctx, cache, query, func(stmt *sqlx.Stmt) error {
+		return stmt.GetContext(ctx, dest, args...)
+	})
+}
+
+// selectContext runs SelectContext, using the statement cache if enabled
+func (p *PostgreSQL) selectContext(ctx context.Context, dest any, query string, args []any) error {
+	cache := p.statementCache()
+	if cache == nil {
+		return p.Queryable().SelectContext(ctx, dest, query, args...)
+	}
+
+	return p.withStatement(ctx, cache, query, func(stmt *sqlx.Stmt) error {
+		return stmt.SelectContext(ctx, dest, args...)
+	})
+}
+
+// execContext runs ExecContext, using the statement cache if enabled
+func (p *PostgreSQL) execContext(ctx context.Context, query string, args []any) (sql.Result, error) {
+	cache := p.statementCache()
+	if cache == nil {
+		return p.Queryable().ExecContext(ctx, query, args...)
+	}
+
+	var result sql.Result
+	err := p.withStatement(ctx, cache, query, func(stmt *sqlx.Stmt) error {
+		var err error
+		result, err = stmt.ExecContext(ctx, args...)
+		return err
+	})
+
+	return result, err
+}
+
+// queryRows runs QueryxContext and calls fn with the rows, using the statement cache if enabled
+func (p *PostgreSQL) queryRows(ctx context.Context, query string, args []any, fn func(rows *sqlx.Rows) error) error {
+	run := func(rows *sqlx.Rows, err error) error {
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		return fn(rows)
+	}
+
+	cache := p.statementCache()
+	if cache == nil {
+		return run(p.Queryable().QueryxContext(ctx, query, args...))
+	}
+
+	return p.withStatement(ctx, cache, query, func(stmt *sqlx.Stmt) error {
+		return run(stmt.QueryxContext(ctx, args...))
+	})
+}
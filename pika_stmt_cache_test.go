@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementCache(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	require.Nil(t, psql.EnableStatementCache(2))
+	defer psql.DisableStatementCache()
+	ctx := context.Background()
+
+	for range 3 {
+		ret, err := Q[simpleModel1](psql).All(ctx)
+		require.Nil(t, err)
+		require.Equal(t, 3, len(ret))
+	}
+
+	stats := psql.StatementCacheStats()
+	require.Equal(t, uint64(2), stats.Hits)
+	require.Equal(t, uint64(1), stats.Misses)
+	require.Equal(t, 1, stats.Size)
+	require.InDelta(t, 2.0/3.0, stats.HitRate(), 0.001)
+
+	// Different queries use different statements
+	x, err := Q[simpleModel1](psql).F("id", 2).Get(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "Test2", x.Title)
+
+	count, err := Q[simpleModel1](psql).Count(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 3, count)
+
+	// The least recently used statement is evicted
+	stats = psql.StatementCacheStats()
+	require.Equal(t, uint64(3), stats.Misses)
+	require.Equal(t, uint64(1), stats.Evictions)
+	require.Equal(t, 2, stats.Size)
+
+	_, err = Q[simpleModel1](psql).All(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(4), psql.StatementCacheStats().Misses)
+}
+
+func TestStatementCacheTransaction(t *testing.T) {
+	psql := newPsql(t)
+	createTestModelCreate(t, psql)
+	require.Nil(t, psql.EnableStatementCache(10))
+	defer psql.DisableStatementCache()
+	ctx := context.Background()
+
+	_, err := Q[simpleModelCreate](psql).All(ctx)
+	require.Nil(t, err)
+
+	err = psql.Begin(ctx)
+	require.Nil(t, err)
+
+	entry := simpleModelCreate{
+		Title:       "test",
+		Description: "test-description",
+	}
+	err = Q[simpleModelCreate](psql).Create(ctx, &entry)
+	require.Nil(t, err)
+
+	// Queries in the transaction see the uncommitted row, and don't use the cache
+	ret, err := Q[simpleModelCreate](psql).All(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(ret))
+	require.Equal(t, uint64(0), psql.StatementCacheStats().Hits)
+
+	err = psql.Rollback()
+	require.Nil(t, err)
+
+	ret, err = Q[simpleModelCreate](psql).All(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 0, len(ret))
+	require.Equal(t, uint64(1), psql.StatementCacheStats().Hits)
+	require.Equal(t, uint64(1), psql.StatementCacheStats().Misses)
+}
+
+func TestStatementCacheTransactionSingleConnection(t *testing.T) {
+	psql := newPsql(t)
+	createTestModelCreate(t, psql)
+	require.Nil(t, psql.EnableStatementCache(10))
+	defer psql.DisableStatementCache()
+
+	// The transaction holds the only connection, so queries must not need another one
+	psql.DB().SetMaxOpenConns(1)
+	defer psql.DB().SetMaxOpenConns(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := psql.Begin(ctx)
+	require.Nil(t, err)
+	defer func() {
+		_ = psql.Rollback()
+	}()
+
+	entry := simpleModelCreate{
+		Title:       "test",
+		Description: "test-description",
+	}
+	err = Q[simpleModelCreate](psql).Create(ctx, &entry)
+	require.Nil(t, err)
+
+	ret, err := Q[simpleModelCreate](psql).All(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 1, len(ret))
+}
+
+func TestStatementCacheInvalidation(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	require.Nil(t, psql.EnableStatementCache(10))
+	defer psql.DisableStatementCache()
+	ctx := context.Background()
+
+	_, err := Q[simpleModel1](psql).All(ctx)
+	require.Nil(t, err)
+
+	// Changing the column type changes the result type of the cached statement
+	psql.db.MustExec("ALTER TABLE simple_model_1 ALTER COLUMN title TYPE VARCHAR(100)")
+
+	ret, err := Q[simpleModel1](psql).All(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(ret))
+
+	stats := psql.StatementCacheStats()
+	require.Equal(t, uint64(1), stats.Invalidations)
+	require.Equal(t, 1, stats.Size)
+}
+
+func TestEnableStatementCacheSize(t *testing.T) {
+	psql := newPsql(t)
+	require.ErrorIs(t, psql.EnableStatementCache(0), ErrStatementCacheSizeTooSmall)
+	require.Equal(t, StatementCacheStats{}, psql.StatementCacheStats())
+}
+
+func TestStatementCacheConcurrentToggle(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	defer psql.DisableStatementCache()
+	ctx := context.Background()
+
+	// Queries may run while the cache is enabled or disabled, run with -race
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				_, err := Q[simpleModel1](psql).All(ctx)
+				require.Nil(t, err)
+			}
+		}()
+	}
+	for range 20 {
+		require.Nil(t, psql.EnableStatementCache(2))
+		_ = psql.StatementCacheStats()
+		psql.DisableStatementCache()
+	}
+	wg.Wait()
+
+	require.Equal(t, StatementCacheStats{}, psql.StatementCacheStats())
+}
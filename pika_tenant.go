@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Static errors for err113 compliance
+var (
+	ErrTenantRequired    = errors.New("tenant is required")
+	ErrTenantInvalidType = errors.New("tenant has invalid type")
+	ErrTenantMismatch    = errors.New("value belongs to another tenant")
+)
+
+// tenantArgKey is the named argument used for the tenant filter
+const tenantArgKey = "pika_tenant"
+
+type tenantContextKey struct{}
+
+type tenantBypassContextKey struct{}
+
+// WithTenant returns a context that scopes queries on tenant models to the given tenant.
+// Overrides the tenant set on the connection.
+func WithTenant(ctx context.Context, tenant any) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// WithoutTenant returns a context that disables tenant scoping.
+// Use with care, queries will return rows of all tenants.
+func WithoutTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantBypassContextKey{}, true)
+}
+
+// TenantFromContext returns the tenant set with WithTenant
+func TenantFromContext(ctx context.Context) (any, bool) {
+	tenant := ctx.Value(tenantContextKey{})
+	return tenant, tenant != nil
+}
+
+// SetTenant sets the tenant used for tenant models on this connection.
+// The tenant from the context takes precedence.
+func (p *PostgreSQL) SetTenant(tenant any) {
+	p.tenant = tenant
+}
+
+// tenantColumn returns the tenant column of the model, or an empty string
+// if the model is not scoped to a tenant
+func (b *basePsql[T]) tenantColumn() string {
+	return b.model.TenantColumn
+}
+
+// currentTenant returns the tenant for the query.
+// Returns false if tenant scoping is bypassed.
+func (b *basePsql[T]) currentTenant(ctx context.Context) (any, bool, error) {
+	if bypass, _ := ctx.Value(tenantBypassContextKey{}).(bool); bypass {
+		return nil, false, nil
+	}
+
+	tenant, ok := TenantFromContext(ctx)
+	if !ok {
+		tenant = b.psql.tenant
+	}
+	if tenant == nil {
+		return nil, false, fmt.Errorf("%w: %s", ErrTenantRequired, b.metadata[pikaMetadataModelName])
+	}
+
+	return tenant, true, nil
+}
+
+// applyTenant scopes the query to the current tenant.
+// Subqueries passed as arguments are scoped as well.
+// Can be called multiple times, the filter is only added once.
+func (b *basePsql[T]) applyTenant(ctx context.Context) error {
+	for pair := b.args.Oldest(); pair != nil; pair = pair.Next() {
+		if sq, ok := pair.Value.(interface{ applyTenant(context.Context) error }); ok {
+			err := sq.applyTenant(ctx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	column := b.tenantColumn()
+	if column == "" {
+		return nil
+	}
+
+	tenant, ok, err := b.currentTenant(ctx)
+	if err != nil || !ok {
+		return err
+	}
+
+	if _, ok := b.args.Get(tenantArgKey); !ok {
+		b.scope(column + "=:" + tenantArgKey)
+	}
+	b.args.Set(tenantArgKey, tenant)
+
+	return nil
+}
+
+// setTenant sets the tenant column of x to the current tenant.
+// Returns ErrTenantMismatch if the tenant column of x is already set to another tenant.
+func (b *basePsql[T]) setTenant(ctx context.Context, x *T) error {
+	column := b.tenantColumn()
+	if column == "" {
+		return nil
+	}
+
+	tenant, ok, err := b.currentTenant(ctx)
+	if err != nil || !ok {
+		return err
+	}
+
+	// The tenant column may not be a field of the model
+	info := b.model.Column(column)
+	if info == nil {
+		return nil
+	}
+
+	field := reflect.ValueOf(x).Elem().Field(info.Index)
+	value := reflect.ValueOf(tenant)
+	if !value.Type().ConvertibleTo(field.Type()) {
+		return fmt.Errorf("%w: %s cannot be used for %s", ErrTenantInvalidType, value.Type(), field.Type())
+	}
+	value = value.Convert(field.Type())
+	if !field.IsZero() && !field.Equal(value) {
+		return fmt.Errorf("%w: %v is not %v", ErrTenantMismatch, field.Interface(), value.Interface())
+	}
+	field.Set(value)
+
+	return nil
+}
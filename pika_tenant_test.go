@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tenantModel struct {
+	PikaTableName    string `pika:"tenant_model"`
+	PikaTenantColumn string `pika:"tenant_id"`
+
+	ID       int    `db:"id" pika:"omitempty"`
+	TenantID string `db:"tenant_id"`
+	Name     string `db:"name"`
+}
+
+func createTenantEntries(t *testing.T, psql *PostgreSQL) {
+	psql.db.MustExec("DROP TABLE IF EXISTS tenant_model")
+	psql.db.MustExec("CREATE TABLE tenant_model (id SERIAL PRIMARY KEY, tenant_id TEXT NOT NULL, name TEXT)")
+	psql.db.MustExec(`
+		INSERT INTO tenant_model (tenant_id, name)
+		VALUES
+		('a', 'Test'),
+		('a', 'Test2'),
+		('b', 'Test3')
+	`)
+}
+
+func TestTenantRequired(t *testing.T) {
+	psql := newPsql(t)
+	createTenantEntries(t, psql)
+	ctx := context.Background()
+
+	_, err := Q[tenantModel](psql).All(ctx)
+	require.ErrorIs(t, err, ErrTenantRequired)
+
+	_, err = Q[tenantModel](psql).Count(ctx)
+	require.ErrorIs(t, err, ErrTenantRequired)
+
+	err = Q[tenantModel](psql).Create(ctx, &tenantModel{Name: "Test4"})
+	require.ErrorIs(t, err, ErrTenantRequired)
+
+	err = Q[tenantModel](psql).F("id", 1).Delete(ctx)
+	require.ErrorIs(t, err, ErrTenantRequired)
+
+	// Models without a tenant column are not affected
+	createTestEntries(t, psql)
+	_, err = Q[simpleModel1](psql).All(ctx)
+	require.Nil(t, err)
+}
+
+func TestTenantQuery(t *testing.T) {
+	qs := PSQLQuery[tenantModel](newPsql(t)).(*basePsql[tenantModel])
+
+	args := NewArgs()
+	args.Set("name", "Test")
+	args.Set("name2", "Test3")
+	qs.Filter("name=:name").FilterOr("name=:name2").Args(args)
+
+	// Applying the tenant twice only adds the filter once
+	err := qs.applyTenant(WithTenant(context.Background(), "a"))
+	require.Nil(t, err)
+	err = qs.applyTenant(WithTenant(context.Background(), "b"))
+	require.Nil(t, err)
+
+	expectedQuery := `SELECT "tenantModel"."id", "tenantModel"."tenant_id", "tenantModel"."name" FROM "tenant_model" "tenantModel" WHERE (("tenantModel"."name" = $1) OR ("tenantModel"."name" = $2)) AND ("tenantModel"."tenant_id" = $3)`
+	expectedArgs := []interface{}{"Test", "Test3", "b"}
+	actualQuery, actualArgs := qs.AllQuery()
+	require.Equal(t, expectedQuery, actualQuery)
+	require.Equal(t, expectedArgs, actualArgs)
+}
+
+func TestTenantScope(t *testing.T) {
+	psql := newPsql(t)
+	createTenantEntries(t, psql)
+	ctx := WithTenant(context.Background(), "a")
+
+	ret, err := Q[tenantModel](psql).OrderBy("id").All(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 2, len(ret))
+	require.Equal(t, "Test", ret[0].Name)
+	require.Equal(t, "Test2", ret[1].Name)
+
+	count, err := Q[tenantModel](psql).Count(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 2, count)
+
+	// Rows of other tenants are not found
+	x, err := Q[tenantModel](psql).F("id", 3).GetOrNil(ctx)
+	require.Nil(t, err)
+	require.Nil(t, x)
+
+	// Bypassing returns rows of all tenants
+	count, err = Q[tenantModel](psql).Count(WithoutTenant(ctx))
+	require.Nil(t, err)
+	require.Equal(t, 3, count)
+}
+
+func TestTenantConnection(t *testing.T) {
+	psql := newPsql(t)
+	createTenantEntries(t, psql)
+	psql.SetTenant("b")
+
+	ret, err := Q[tenantModel](psql).All(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 1, len(ret))
+	require.Equal(t, "Test3", ret[0].Name)
+
+	// The tenant from the context takes precedence
+	count, err := Q[tenantModel](psql).Count(WithTenant(context.Background(), "a"))
+	require.Nil(t, err)
+	require.Equal(t, 2, count)
+}
+
+func TestTenantCreate(t *testing.T) {
+	psql := newPsql(t)
+	createTenantEntries(t, psql)
+	ctx := WithTenant(context.Background(), "b")
+
+	// Values of other tenants are not silently moved
+	entry := tenantModel{
+		TenantID: "a",
+		Name:     "Test4",
+	}
+	err := Q[tenantModel](psql).Create(ctx, &entry)
+	require.ErrorIs(t, err, ErrTenantMismatch)
+	require.Equal(t, "a", entry.TenantID)
+
+	entry.TenantID = ""
+	err = Q[tenantModel](psql).Create(ctx, &entry)
+	require.Nil(t, err)
+	require.Equal(t, "b", entry.TenantID)
+
+	count, err := Q[tenantModel](psql).Count(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 2, count)
+
+	entry.TenantID = ""
+	err = Q[tenantModel](psql).Create(WithTenant(ctx, 1), &entry)
+	require.ErrorIs(t, err, ErrTenantInvalidType)
+}
+
+func TestTenantUpdateDelete(t *testing.T) {
+	psql := newPsql(t)
+	createTenantEntries(t, psql)
+	ctx := WithTenant(context.Background(), "b")
+
+	// Rows of other tenants can't be updated
+	entry := tenantModel{
+		ID:   1,
+		Name: "Updated",
+	}
+	err := Q[tenantModel](psql).U(ctx, &entry)
+	require.Error(t, err)
+
+	err = Q[tenantModel](psql).F("id", 1).Delete(ctx)
+	require.Nil(t, err)
+
+	count, err := Q[tenantModel](psql).Count(WithoutTenant(ctx))
+	require.Nil(t, err)
+	require.Equal(t, 3, count)
+
+	// Rows of the tenant can
+	entry.ID = 3
+	err = Q[tenantModel](psql).U(ctx, &entry)
+	require.Nil(t, err)
+	require.Equal(t, "b", entry.TenantID)
+
+	entry.TenantID = "a"
+	err = Q[tenantModel](psql).U(ctx, &entry)
+	require.ErrorIs(t, err, ErrTenantMismatch)
+
+	err = Q[tenantModel](psql).F("id", 3).Delete(ctx)
+	require.Nil(t, err)
+
+	count, err = Q[tenantModel](psql).Count(WithoutTenant(ctx))
+	require.Nil(t, err)
+	require.Equal(t, 2, count)
+}
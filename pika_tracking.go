@@ -0,0 +1,216 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Static errors for err113 compliance
+var (
+	ErrNotTracked       = errors.New("value is not tracked")
+	ErrUnknownColumn    = errors.New("unknown column")
+	ErrPrimaryKeyUpdate = errors.New("primary key columns cannot be updated")
+)
+
+// Tracker remembers the column values of loaded values, so Save only updates the columns that changed.
+// The tracker is owned by the caller and keeps the tracked values alive until they are forgotten
+// or the tracker is discarded. It is safe for concurrent use.
+type Tracker[T any] struct {
+	mu        sync.Mutex
+	snapshots map[*T]map[string]any
+}
+
+// NewTracker returns an empty tracker
+func NewTracker[T any]() *Tracker[T] {
+	return &Tracker[T]{
+		snapshots: map[*T]map[string]any{},
+	}
+}
+
+// Forget stops tracking the given values
+func (t *Tracker[T]) Forget(values ...*T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, x := range values {
+		delete(t.snapshots, x)
+	}
+}
+
+func (t *Tracker[T]) load(x *T) (map[string]any, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snapshot, ok := t.snapshots[x]
+	return snapshot, ok
+}
+
+func (t *Tracker[T]) store(x *T, snapshot map[string]any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.snapshots[x] = snapshot
+}
+
+// Track remembers the column values of loaded values in tracker
+func (b *basePsql[T]) Track(tracker *Tracker[T]) QuerySet[T] {
+	if b.err != nil {
+		return b
+	}
+
+	b.tracker = tracker
+	return b
+}
+
+// Save updates the columns of a value tracked by tracker that changed since it was loaded
+func (b *basePsql[T]) Save(ctx context.Context, tracker *Tracker[T], x *T) error {
+	if b.err != nil {
+		return b.err
+	}
+
+	original, ok := tracker.load(x)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotTracked, b.model.Name)
+	}
+
+	// Compare in column order, so the query is the same for the same changes
+	var changed []string
+	current := b.snapshot(x)
+	for _, column := range b.model.Columns {
+		if column.PrimaryKey {
+			continue
+		}
+		if !reflect.DeepEqual(original[column.Name], current[column.Name]) {
+			changed = append(changed, column.Name)
+		}
+	}
+
+	// Nothing to do
+	if len(changed) == 0 {
+		return nil
+	}
+
+	err := b.UpdateFields(ctx, x, changed...)
+	if err != nil {
+		return err
+	}
+
+	// The updated row is scanned into x
+	tracker.store(x, b.snapshot(x))
+
+	return nil
+}
+
+// UpdateFields updates the given columns of a value, filtered by the primary key
+func (b *basePsql[T]) UpdateFields(ctx context.Context, x *T, columns ...string) error {
+	if b.err != nil {
+		return b.err
+	}
+
+	if len(columns) == 0 {
+		return nil
+	}
+
+	for _, name := range columns {
+		column := b.model.Column(name)
+		if column == nil {
+			return fmt.Errorf("%w: %s", ErrUnknownColumn, name)
+		}
+		if column.PrimaryKey {
+			return fmt.Errorf("%w: %s", ErrPrimaryKeyUpdate, name)
+		}
+	}
+
+	pk, err := b.primaryKeyValues(x)
+	if err != nil {
+		return err
+	}
+
+	qs, err := b.filterPrimaryKey(pk)
+	if err != nil {
+		return err
+	}
+
+	origUpdateColumns := b.updateColumns
+	b.updateColumns = columns
+	defer func() {
+		b.updateColumns = origUpdateColumns
+	}()
+
+	return qs.Update(ctx, x)
+}
+
+// afterFind calls the AfterFind hook of x, and tracks x if a tracker is set.
+// The values are tracked before the hook is called, as they are loaded from the database.
+func (b *basePsql[T]) afterFind(ctx context.Context, x *T) error {
+	if b.tracker != nil {
+		b.tracker.store(x, b.snapshot(x))
+	}
+
+	return afterFind(ctx, x)
+}
+
+// retrack updates the tracked column values of x, if x is tracked by the tracker of the query set
+func (b *basePsql[T]) retrack(x *T) {
+	if b.tracker == nil {
+		return
+	}
+	if _, ok := b.tracker.load(x); ok {
+		b.tracker.store(x, b.snapshot(x))
+	}
+}
+
+// snapshot returns a copy of the column values of x, excluding the primary key
+func (b *basePsql[T]) snapshot(x *T) map[string]any {
+	elem := reflect.ValueOf(x).Elem()
+
+	values := make(map[string]any, len(b.model.Columns))
+	for _, column := range b.model.Columns {
+		if column.PrimaryKey {
+			continue
+		}
+		values[column.Name] = copyValue(elem.Field(column.Index))
+	}
+
+	return values
+}
+
+// copyValue returns a copy of v, so changes to slices, maps and pointers
+// of the original value are not reflected in the copy
+func copyValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		return c.Interface()
+	case reflect.Map:
+		if v.IsNil() {
+			break
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return c.Interface()
+	case reflect.Pointer:
+		if v.IsNil() {
+			break
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(v.Elem())
+		return c.Interface()
+	}
+
+	return v.Interface()
+}
@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type omitEmptyModel struct {
+	PikaTableName string `pika:"simple_model_1"`
+
+	ID          int    `db:"id" pika:"omitempty"`
+	Title       string `db:"title"`
+	Description string `db:"description" pika:"omitempty"`
+}
+
+func TestSave(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	hook := &recordingHook{}
+	psql.AddQueryHook(hook)
+	ctx := context.Background()
+
+	tracker := NewTracker[simpleModel1]()
+	x, err := Q[simpleModel1](psql).Track(tracker).F("id", 2).Get(ctx)
+	require.Nil(t, err)
+
+	x.Description = "Changed"
+	err = Q[simpleModel1](psql).Save(ctx, tracker, x)
+	require.Nil(t, err)
+
+	require.Equal(t, []string{OperationGet, OperationUpdate}, hook.operations())
+	expectedQuery := `UPDATE "simple_model_1" SET "description" = $2 WHERE ("id" = $1) RETURNING "id", "title", "description"`
+	require.Equal(t, expectedQuery, hook.after[1].Query)
+	require.Equal(t, []any{2, "Changed"}, hook.after[1].Args)
+
+	// Nothing changed since the last save
+	err = Q[simpleModel1](psql).Save(ctx, tracker, x)
+	require.Nil(t, err)
+	require.Len(t, hook.after, 2)
+
+	ret, err := Q[simpleModel1](psql).F("id", 2).Get(ctx)
+	require.Nil(t, err)
+	require.Equal(t, "Test2", ret.Title)
+	require.Equal(t, "Changed", ret.Description)
+}
+
+func TestSaveAll(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	hook := &recordingHook{}
+	psql.AddQueryHook(hook)
+	ctx := context.Background()
+
+	tracker := NewTracker[simpleModel1]()
+	ret, err := Q[simpleModel1](psql).Track(tracker).OrderBy("id").All(ctx)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(ret))
+
+	ret[2].Title = ""
+	for _, x := range ret {
+		err = Q[simpleModel1](psql).Save(ctx, tracker, x)
+		require.Nil(t, err)
+	}
+
+	require.Equal(t, []string{OperationAll, OperationUpdate}, hook.operations())
+	require.Equal(t, []any{3, ""}, hook.after[1].Args)
+}
+
+func TestSaveNotTracked(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	ctx := context.Background()
+
+	tracker := NewTracker[simpleModel1]()
+	x, err := Q[simpleModel1](psql).F("id", 2).Get(ctx)
+	require.Nil(t, err)
+
+	err = Q[simpleModel1](psql).Save(ctx, tracker, x)
+	require.ErrorIs(t, err, ErrNotTracked)
+
+	err = Q[simpleModel1](psql).Save(ctx, tracker, &simpleModel1{ID: 2})
+	require.ErrorIs(t, err, ErrNotTracked)
+
+	// Values tracked by another tracker or forgotten are not tracked
+	x, err = Q[simpleModel1](psql).Track(NewTracker[simpleModel1]()).F("id", 2).Get(ctx)
+	require.Nil(t, err)
+	err = Q[simpleModel1](psql).Save(ctx, tracker, x)
+	require.ErrorIs(t, err, ErrNotTracked)
+
+	x, err = Q[simpleModel1](psql).Track(tracker).F("id", 2).Get(ctx)
+	require.Nil(t, err)
+	tracker.Forget(x)
+	err = Q[simpleModel1](psql).Save(ctx, tracker, x)
+	require.ErrorIs(t, err, ErrNotTracked)
+}
+
+func TestTrackerNoFinalizer(t *testing.T) {
+	tracker := NewTracker[simpleModel1]()
+	qs := Q[simpleModel1](newPsql(t)).Track(tracker).(*basePsql[simpleModel1])
+
+	// Values owned by the caller may have their own finalizer
+	x := &simpleModel1{ID: 1, Title: "Test"}
+	runtime.SetFinalizer(x, func(*simpleModel1) {})
+	err := qs.afterFind(context.Background(), x)
+	require.Nil(t, err)
+
+	snapshot, ok := tracker.load(x)
+	require.True(t, ok)
+	require.Equal(t, "Test", snapshot["title"])
+	runtime.SetFinalizer(x, nil)
+}
+
+func TestUpdateFields(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	hook := &recordingHook{}
+	psql.AddQueryHook(hook)
+	ctx := context.Background()
+
+	// Empty values are written even with omitempty
+	x := &omitEmptyModel{ID: 1, Title: "Changed"}
+	err := Q[omitEmptyModel](psql).UpdateFields(ctx, x, "description")
+	require.Nil(t, err)
+
+	expectedQuery := `UPDATE "simple_model_1" SET "description" = $2 WHERE ("id" = $1) RETURNING "id", "title", "description"`
+	require.Equal(t, expectedQuery, hook.after[0].Query)
+	require.Equal(t, []any{1, ""}, hook.after[0].Args)
+
+	// The returned row is scanned into the value
+	require.Equal(t, "Test", x.Title)
+	require.Equal(t, "", x.Description)
+
+	err = Q[omitEmptyModel](psql).UpdateFields(ctx, x, "does_not_exist")
+	require.ErrorIs(t, err, ErrUnknownColumn)
+
+	err = Q[omitEmptyModel](psql).UpdateFields(ctx, x, "id")
+	require.ErrorIs(t, err, ErrPrimaryKeyUpdate)
+
+	// No columns, no query
+	err = Q[omitEmptyModel](psql).UpdateFields(ctx, x)
+	require.Nil(t, err)
+	require.Len(t, hook.after, 1)
+}
+
+func TestCopyValue(t *testing.T) {
+	s := []string{"a"}
+	c := copyValue(reflect.ValueOf(s))
+	s[0] = "b"
+	require.Equal(t, []string{"a"}, c)
+
+	m := map[string]int{"a": 1}
+	c = copyValue(reflect.ValueOf(m))
+	m["a"] = 2
+	require.Equal(t, map[string]int{"a": 1}, c)
+
+	v := "a"
+	p := &v
+	c = copyValue(reflect.ValueOf(p))
+	*p = "b"
+	require.Equal(t, "a", *c.(*string))
+
+	require.Nil(t, copyValue(reflect.ValueOf([]string(nil))).([]string))
+}
@@ -0,0 +1,292 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Static errors for err113 compliance
+var (
+	ErrSchemaMismatch = errors.New("schema does not match models")
+)
+
+// scannerType is used to find types that handle NULL and database types themselves
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// AIPModel is a model with the AIP filter options used for it.
+// Pass to Verify to also verify the column names of the identifiers.
+type AIPModel struct {
+	Model   any
+	Options AIPFilterOptions
+}
+
+// VerifyProblem is a mismatch between a model and its table
+type VerifyProblem struct {
+	// Model is the name of the model type
+	Model string
+	// Table is the table of the model
+	Table string
+	// Column is the column, empty if the problem is with the table
+	Column string
+	// Message describes the problem
+	Message string
+}
+
+func (p *VerifyProblem) String() string {
+	if p.Column == "" {
+		return fmt.Sprintf("%s (%s): %s", p.Model, p.Table, p.Message)
+	}
+
+	return fmt.Sprintf("%s (%s.%s): %s", p.Model, p.Table, p.Column, p.Message)
+}
+
+// VerifyError contains all problems found by Verify
+type VerifyError struct {
+	Problems []*VerifyProblem
+}
+
+func (e *VerifyError) Error() string {
+	lines := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		lines = append(lines, problem.String())
+	}
+
+	return fmt.Sprintf("%s: %s", ErrSchemaMismatch, strings.Join(lines, "; "))
+}
+
+// Unwrap makes errors.Is(err, ErrSchemaMismatch) work
+func (e *VerifyError) Unwrap() error {
+	return ErrSchemaMismatch
+}
+
+// Verify checks that the tables of the models exist, that every db tagged column exists
+// with a compatible type, that nullable columns use Go types that can hold NULL, and that
+// PikaDefaultOrderBy, PikaTenantColumn and the column names of AIP filter identifiers
+// refer to existing columns.
+// Returns a *VerifyError with all problems found, or other errors if the database can't be queried.
+// Models are passed as values or pointers, or as AIPModel to also verify the AIP filter options.
+// Example:
+//
+//	err := psql.Verify(ctx, User{}, AIPModel{Model: Group{}, Options: groupOptions})
+func (p *PostgreSQL) Verify(ctx context.Context, models ...any) error {
+	var problems []*VerifyProblem
+	for _, model := range models {
+		var options *AIPFilterOptions
+		if aipModel, ok := model.(AIPModel); ok {
+			model = aipModel.Model
+			options = &aipModel.Options
+		}
+
+		t := reflect.TypeOf(model)
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		info := modelOf(t)
+
+		table, err := p.loadTable(ctx, info)
+		if err != nil {
+			return err
+		}
+
+		problems = append(problems, verifyModel(info, table, options)...)
+	}
+
+	if len(problems) > 0 {
+		return &VerifyError{Problems: problems}
+	}
+
+	return nil
+}
+
+func verifyModel(info *ModelInfo, table *dbTable, options *AIPFilterOptions) []*VerifyProblem {
+	var problems []*VerifyProblem
+	problem := func(column string, format string, args ...any) {
+		problems = append(problems, &VerifyProblem{
+			Model:   info.Name,
+			Table:   table.name,
+			Column:  column,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if len(table.columns) == 0 {
+		problem("", "table does not exist")
+		return problems
+	}
+
+	for _, column := range info.Columns {
+		// Columns of other tables are verified with their own model
+		if strings.Contains(column.PikaName, ".") {
+			continue
+		}
+
+		existing, ok := table.columns[column.Name]
+		if !ok {
+			problem(column.Name, "column does not exist")
+			continue
+		}
+
+		if !compatibleType(column, existing) {
+			problem(column.Name, "%s is not compatible with column type %s", column.Type, existing.UDTName)
+		}
+
+		if existing.Nullable == "YES" && !nullableType(column.Type) {
+			problem(column.Name, "column is nullable, but %s can't hold NULL", column.Type)
+		}
+	}
+
+	if orderBy := info.DefaultOrderBy; orderBy != "" {
+		column := strings.TrimPrefix(orderBy, "-")
+		if _, ok := table.columns[column]; !ok {
+			problem(column, "%s refers to a column that does not exist", PikaMetadataDefaultOrderBy)
+		}
+	}
+
+	if column := info.TenantColumn; column != "" {
+		if _, ok := table.columns[column]; !ok {
+			problem(column, "%s refers to a column that does not exist", PikaMetadataTenantColumn)
+		}
+	}
+
+	if options != nil {
+		identifiers := make([]string, 0, len(options.Identifiers))
+		for identifier := range options.Identifiers {
+			identifiers = append(identifiers, identifier)
+		}
+		sort.Strings(identifiers)
+
+		for _, identifier := range identifiers {
+			// JSON paths are verified by their column
+			column, _, _ := strings.Cut(options.Identifiers[identifier].ColumnName, "->")
+			column, ok := tableColumn(info, table, column)
+			if column == "" || !ok {
+				continue
+			}
+			if _, ok := table.columns[column]; !ok {
+				problem(column, "column of AIP filter identifier %s does not exist", identifier)
+			}
+		}
+	}
+
+	return problems
+}
+
+// tableColumn returns the column without a table qualifier, for example title for simpleModel1.title.
+// Returns false if the column is qualified with another table, such as a joined table,
+// as those columns are verified with their own model.
+func tableColumn(info *ModelInfo, table *dbTable, column string) (string, bool) {
+	i := strings.LastIndex(column, ".")
+	if i < 0 {
+		return column, true
+	}
+
+	qualifier := strings.Trim(column[:i], "\"")
+	column = strings.Trim(column[i+1:], "\"")
+	if qualifier == info.Name || unqualifiedTableName(qualifier) == table.name {
+		return column, true
+	}
+
+	return column, false
+}
+
+// compatibleType returns true if values of the column can be scanned into the Go type
+func compatibleType(column *ColumnInfo, existing *dbColumn) bool {
+	sqlType := column.SQLType
+	if sqlType == "" {
+		t := column.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		// Types with their own Scan method are not known
+		if _, ok := sqlTypes[t]; !ok && reflect.PointerTo(t).Implements(scannerType) {
+			return true
+		}
+
+		var err error
+		sqlType, _, err = goSQLType(t)
+		if err != nil {
+			return false
+		}
+	}
+
+	want := typeFamily(udtName(sqlType))
+	got := typeFamily(existing.UDTName)
+	if want == got {
+		return true
+	}
+
+	// Numeric columns can be scanned into wider Go types, for example an integer column into a float.
+	// Numeric columns only fit into integers without fractional digits.
+	switch want {
+	case "string":
+		// Enums
+		return existing.DataType == "USER-DEFINED"
+	case "integer":
+		return got == "numeric" && existing.Scale.Valid && existing.Scale.Int64 == 0
+	case "float":
+		return got == "numeric" || got == "integer"
+	case "numeric":
+		return got == "integer"
+	case "bytea":
+		return got == "json" || got == "string"
+	}
+
+	return false
+}
+
+// typeFamilies groups udt names of types that can be scanned into the same Go types
+var typeFamilies = map[string]string{
+	"int2":        "integer",
+	"int4":        "integer",
+	"int8":        "integer",
+	"float4":      "float",
+	"float8":      "float",
+	"text":        "string",
+	"varchar":     "string",
+	"bpchar":      "string",
+	"citext":      "string",
+	"name":        "string",
+	"uuid":        "string",
+	"timestamptz": "time",
+	"timestamp":   "time",
+	"date":        "time",
+	"json":        "json",
+	"jsonb":       "json",
+}
+
+// typeFamily returns the family of a udt name, or the name itself
+func typeFamily(udt string) string {
+	if element, ok := strings.CutPrefix(udt, "_"); ok {
+		return "_" + typeFamily(element)
+	}
+	if family, ok := typeFamilies[udt]; ok {
+		return family
+	}
+
+	return udt
+}
+
+// nullableType returns true if the Go type can hold NULL
+func nullableType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+
+	if x, ok := sqlTypes[t]; ok {
+		return x.nullable
+	}
+
+	// Types with their own Scan method are trusted to handle NULL
+	return reflect.PointerTo(t).Implements(scannerType)
+}
@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+package pika
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type verifyModel1 struct {
+	PikaTableName      string `pika:"simple_model_1"`
+	PikaDefaultOrderBy string `pika:"-created_at"`
+
+	ID          int    `db:"id"`
+	Title       string `db:"title"`
+	Description string `db:"description"`
+	Count       int    `db:"count"`
+}
+
+type verifyModel2 struct {
+	PikaTableName string `pika:"simple_model_1"`
+
+	ID          int            `db:"id"`
+	Title       sql.NullString `db:"title"`
+	Description *string        `db:"description"`
+}
+
+func verifyTable(columns ...*dbColumn) *dbTable {
+	table := &dbTable{
+		name:    "simple_model_1",
+		columns: map[string]*dbColumn{},
+	}
+	for _, column := range columns {
+		table.columns[column.Name] = column
+	}
+
+	return table
+}
+
+func TestVerifyModel(t *testing.T) {
+	table := verifyTable(
+		&dbColumn{Name: "id", DataType: "integer", UDTName: "int4", Nullable: "NO"},
+		&dbColumn{Name: "title", DataType: "text", UDTName: "text", Nullable: "YES"},
+		&dbColumn{Name: "description", DataType: "text", UDTName: "text", Nullable: "YES"},
+	)
+
+	problems := verifyModel(Model[verifyModel2](), table, nil)
+	require.Empty(t, problems)
+
+	problems = verifyModel(Model[verifyModel1](), table, nil)
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+	require.Equal(t, []string{
+		"verifyModel1 (simple_model_1.title): column is nullable, but string can't hold NULL",
+		"verifyModel1 (simple_model_1.description): column is nullable, but string can't hold NULL",
+		"verifyModel1 (simple_model_1.count): column does not exist",
+		"verifyModel1 (simple_model_1.created_at): PikaDefaultOrderBy refers to a column that does not exist",
+	}, messages)
+
+	problems = verifyModel(Model[verifyModel1](), verifyTable(), nil)
+	require.Len(t, problems, 1)
+	require.Equal(t, "verifyModel1 (simple_model_1): table does not exist", problems[0].String())
+}
+
+func TestVerifyModelTypes(t *testing.T) {
+	table := verifyTable(
+		&dbColumn{Name: "id", DataType: "bigint", UDTName: "int8", Nullable: "NO"},
+		&dbColumn{Name: "title", DataType: "integer", UDTName: "int4", Nullable: "YES"},
+		&dbColumn{Name: "description", DataType: "USER-DEFINED", UDTName: "status", Nullable: "YES"},
+	)
+
+	problems := verifyModel(Model[verifyModel2](), table, nil)
+	require.Len(t, problems, 1)
+	require.Equal(t, "title", problems[0].Column)
+	require.Equal(t, "sql.NullString is not compatible with column type int4", problems[0].Message)
+
+	// Numeric columns can be read into wider types
+	type widening struct {
+		ID    float64 `db:"id"`
+		Count uint64  `db:"count"`
+		Score int64   `db:"score"`
+	}
+	table = verifyTable(
+		&dbColumn{Name: "id", DataType: "integer", UDTName: "int4", Nullable: "NO"},
+		&dbColumn{Name: "count", DataType: "bigint", UDTName: "int8", Nullable: "NO"},
+		&dbColumn{Name: "score", DataType: "double precision", UDTName: "float8", Nullable: "NO"},
+	)
+	problems = verifyModel(Model[widening](), table, nil)
+	require.Len(t, problems, 1)
+	require.Equal(t, "score", problems[0].Column)
+	require.Equal(t, "int64 is not compatible with column type float8", problems[0].Message)
+
+	// Integers can only hold numeric columns without fractional digits
+	type numeric struct {
+		ID     int64 `db:"id"`
+		Amount int64 `db:"amount"`
+		Total  int64 `db:"total"`
+	}
+	table = verifyTable(
+		&dbColumn{Name: "id", DataType: "numeric", UDTName: "numeric", Nullable: "NO", Scale: sql.NullInt64{Int64: 0, Valid: true}},
+		&dbColumn{Name: "amount", DataType: "numeric", UDTName: "numeric", Nullable: "NO", Scale: sql.NullInt64{Int64: 2, Valid: true}},
+		&dbColumn{Name: "total", DataType: "numeric", UDTName: "numeric", Nullable: "NO"},
+	)
+	problems = verifyModel(Model[numeric](), table, nil)
+	require.Len(t, problems, 2)
+	require.Equal(t, "amount", problems[0].Column)
+	require.Equal(t, "int64 is not compatible with column type numeric", problems[0].Message)
+	require.Equal(t, "total", problems[1].Column)
+}
+
+func TestVerifyModelAIPOptions(t *testing.T) {
+	table := verifyTable(
+		&dbColumn{Name: "id", DataType: "integer", UDTName: "int4", Nullable: "NO"},
+		&dbColumn{Name: "title", DataType: "text", UDTName: "text", Nullable: "YES"},
+		&dbColumn{Name: "description", DataType: "text", UDTName: "text", Nullable: "YES"},
+	)
+
+	options := &AIPFilterOptions{
+		Identifiers: map[string]AIPFilterIdentifier{
+			"name":    {ColumnName: "title"},
+			"summary": {ColumnName: "summary"},
+			"labels":  {ColumnName: "description->labels", IsMap: true},
+			// Qualified with the model or the table
+			"heading":  {ColumnName: "verifyModel2.title"},
+			"subtitle": {ColumnName: `"simple_model_1"."subtitle"`},
+			// Columns of joined tables are verified with their model
+			"author": {ColumnName: "users.name"},
+		},
+	}
+	problems := verifyModel(Model[verifyModel2](), table, options)
+	require.Len(t, problems, 2)
+	require.Equal(t, "verifyModel2 (simple_model_1.subtitle): column of AIP filter identifier subtitle does not exist", problems[0].String())
+	require.Equal(t, "verifyModel2 (simple_model_1.summary): column of AIP filter identifier summary does not exist", problems[1].String())
+}
+
+func TestVerify(t *testing.T) {
+	psql := newPsql(t)
+	createTestEntries(t, psql)
+	psql.db.MustExec("DROP TABLE IF EXISTS tenant_model")
+	ctx := context.Background()
+
+	err := psql.Verify(ctx, verifyModel2{}, &tenantModel{})
+	require.NotNil(t, err)
+
+	var verifyErr *VerifyError
+	require.ErrorAs(t, err, &verifyErr)
+	require.ErrorIs(t, err, ErrSchemaMismatch)
+	require.Len(t, verifyErr.Problems, 1)
+	require.Equal(t, "tenant_model", verifyErr.Problems[0].Table)
+
+	// The name column of tenant_model is nullable
+	createTenantEntries(t, psql)
+	err = psql.Verify(ctx, tenantModel{})
+	require.ErrorAs(t, err, &verifyErr)
+	require.Len(t, verifyErr.Problems, 1)
+	require.Equal(t, "name", verifyErr.Problems[0].Column)
+
+	options := AIPFilterOptions{
+		Identifiers: map[string]AIPFilterIdentifier{
+			"name": {ColumnName: "title"},
+		},
+	}
+	err = psql.Verify(ctx, AIPModel{Model: verifyModel2{}, Options: options})
+	require.Nil(t, err)
+
+	err = psql.Verify(ctx, verifyModel1{})
+	require.ErrorIs(t, err, ErrSchemaMismatch)
+}
@@ -0,0 +1,8 @@
+// SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pikapb contains the protobuf options used by ProtoReflect.
+// Import pika/options.proto from the proto directory to use them.
+//
+//go:generate protoc -I ../proto --go_opt=module=go.ciq.dev/pika/pikapb --go_out=. pika/options.proto
+package pikapb
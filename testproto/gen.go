@@ -1,5 +1,5 @@
 // SPDX-FileCopyrightText: Copyright (c) 2023-2025, CTRL IQ, Inc. All rights reserved
 // SPDX-License-Identifier: Apache-2.0
 
-//go:generate protoc --go_opt=paths=source_relative --go_out=. test.proto
+//go:generate protoc -I . -I ../proto -I third_party --go_opt=paths=source_relative --go_out=. --go-grpc_opt=paths=source_relative --go-grpc_out=. test.proto
 package pikatestpb
@@ -0,0 +1,274 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.21.12
+// source: test.proto
+
+package pikatestpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SimpleModel1Service_ListSimpleModel1_FullMethodName   = "/SimpleModel1Service/ListSimpleModel1"
+	SimpleModel1Service_GetSimpleModel1_FullMethodName    = "/SimpleModel1Service/GetSimpleModel1"
+	SimpleModel1Service_CreateSimpleModel1_FullMethodName = "/SimpleModel1Service/CreateSimpleModel1"
+	SimpleModel1Service_UpdateSimpleModel1_FullMethodName = "/SimpleModel1Service/UpdateSimpleModel1"
+	SimpleModel1Service_DeleteSimpleModel1_FullMethodName = "/SimpleModel1Service/DeleteSimpleModel1"
+)
+
+// SimpleModel1ServiceClient is the client API for SimpleModel1Service service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SimpleModel1ServiceClient interface {
+	ListSimpleModel1(ctx context.Context, in *TestRequest1, opts ...grpc.CallOption) (*ListSimpleModel1Response, error)
+	GetSimpleModel1(ctx context.Context, in *GetSimpleModel1Request, opts ...grpc.CallOption) (*SimpleModel1, error)
+	CreateSimpleModel1(ctx context.Context, in *CreateSimpleModel1Request, opts ...grpc.CallOption) (*SimpleModel1, error)
+	UpdateSimpleModel1(ctx context.Context, in *UpdateSimpleModel1Request, opts ...grpc.CallOption) (*SimpleModel1, error)
+	DeleteSimpleModel1(ctx context.Context, in *DeleteSimpleModel1Request, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type simpleModel1ServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSimpleModel1ServiceClient(cc grpc.ClientConnInterface) SimpleModel1ServiceClient {
+	return &simpleModel1ServiceClient{cc}
+}
+
+func (c *simpleModel1ServiceClient) ListSimpleModel1(ctx context.Context, in *TestRequest1, opts ...grpc.CallOption) (*ListSimpleModel1Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSimpleModel1Response)
+	err := c.cc.Invoke(ctx, SimpleModel1Service_ListSimpleModel1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleModel1ServiceClient) GetSimpleModel1(ctx context.Context, in *GetSimpleModel1Request, opts ...grpc.CallOption) (*SimpleModel1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleModel1)
+	err := c.cc.Invoke(ctx, SimpleModel1Service_GetSimpleModel1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleModel1ServiceClient) CreateSimpleModel1(ctx context.Context, in *CreateSimpleModel1Request, opts ...grpc.CallOption) (*SimpleModel1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleModel1)
+	err := c.cc.Invoke(ctx, SimpleModel1Service_CreateSimpleModel1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleModel1ServiceClient) UpdateSimpleModel1(ctx context.Context, in *UpdateSimpleModel1Request, opts ...grpc.CallOption) (*SimpleModel1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimpleModel1)
+	err := c.cc.Invoke(ctx, SimpleModel1Service_UpdateSimpleModel1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simpleModel1ServiceClient) DeleteSimpleModel1(ctx context.Context, in *DeleteSimpleModel1Request, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SimpleModel1Service_DeleteSimpleModel1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SimpleModel1ServiceServer is the server API for SimpleModel1Service service.
+// All implementations must embed UnimplementedSimpleModel1ServiceServer
+// for forward compatibility.
+type SimpleModel1ServiceServer interface {
+	ListSimpleModel1(context.Context, *TestRequest1) (*ListSimpleModel1Response, error)
+	GetSimpleModel1(context.Context, *GetSimpleModel1Request) (*SimpleModel1, error)
+	CreateSimpleModel1(context.Context, *CreateSimpleModel1Request) (*SimpleModel1, error)
+	UpdateSimpleModel1(context.Context, *UpdateSimpleModel1Request) (*SimpleModel1, error)
+	DeleteSimpleModel1(context.Context, *DeleteSimpleModel1Request) (*emptypb.Empty, error)
+	mustEmbedUnimplementedSimpleModel1ServiceServer()
+}
+
+// UnimplementedSimpleModel1ServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSimpleModel1ServiceServer struct{}
+
+func (UnimplementedSimpleModel1ServiceServer) ListSimpleModel1(context.Context, *TestRequest1) (*ListSimpleModel1Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSimpleModel1 not implemented")
+}
+func (UnimplementedSimpleModel1ServiceServer) GetSimpleModel1(context.Context, *GetSimpleModel1Request) (*SimpleModel1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSimpleModel1 not implemented")
+}
+func (UnimplementedSimpleModel1ServiceServer) CreateSimpleModel1(context.Context, *CreateSimpleModel1Request) (*SimpleModel1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSimpleModel1 not implemented")
+}
+func (UnimplementedSimpleModel1ServiceServer) UpdateSimpleModel1(context.Context, *UpdateSimpleModel1Request) (*SimpleModel1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSimpleModel1 not implemented")
+}
+func (UnimplementedSimpleModel1ServiceServer) DeleteSimpleModel1(context.Context, *DeleteSimpleModel1Request) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSimpleModel1 not implemented")
+}
+func (UnimplementedSimpleModel1ServiceServer) mustEmbedUnimplementedSimpleModel1ServiceServer() {}
+func (UnimplementedSimpleModel1ServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeSimpleModel1ServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SimpleModel1ServiceServer will
+// result in compilation errors.
+type UnsafeSimpleModel1ServiceServer interface {
+	mustEmbedUnimplementedSimpleModel1ServiceServer()
+}
+
+func RegisterSimpleModel1ServiceServer(s grpc.ServiceRegistrar, srv SimpleModel1ServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSimpleModel1ServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SimpleModel1Service_ServiceDesc, srv)
+}
+
+func _SimpleModel1Service_ListSimpleModel1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TestRequest1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleModel1ServiceServer).ListSimpleModel1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleModel1Service_ListSimpleModel1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleModel1ServiceServer).ListSimpleModel1(ctx, req.(*TestRequest1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleModel1Service_GetSimpleModel1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSimpleModel1Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleModel1ServiceServer).GetSimpleModel1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleModel1Service_GetSimpleModel1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleModel1ServiceServer).GetSimpleModel1(ctx, req.(*GetSimpleModel1Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleModel1Service_CreateSimpleModel1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSimpleModel1Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleModel1ServiceServer).CreateSimpleModel1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleModel1Service_CreateSimpleModel1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleModel1ServiceServer).CreateSimpleModel1(ctx, req.(*CreateSimpleModel1Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleModel1Service_UpdateSimpleModel1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSimpleModel1Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleModel1ServiceServer).UpdateSimpleModel1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleModel1Service_UpdateSimpleModel1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleModel1ServiceServer).UpdateSimpleModel1(ctx, req.(*UpdateSimpleModel1Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SimpleModel1Service_DeleteSimpleModel1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSimpleModel1Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimpleModel1ServiceServer).DeleteSimpleModel1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SimpleModel1Service_DeleteSimpleModel1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimpleModel1ServiceServer).DeleteSimpleModel1(ctx, req.(*DeleteSimpleModel1Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SimpleModel1Service_ServiceDesc is the grpc.ServiceDesc for SimpleModel1Service service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SimpleModel1Service_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "SimpleModel1Service",
+	HandlerType: (*SimpleModel1ServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListSimpleModel1",
+			Handler:    _SimpleModel1Service_ListSimpleModel1_Handler,
+		},
+		{
+			MethodName: "GetSimpleModel1",
+			Handler:    _SimpleModel1Service_GetSimpleModel1_Handler,
+		},
+		{
+			MethodName: "CreateSimpleModel1",
+			Handler:    _SimpleModel1Service_CreateSimpleModel1_Handler,
+		},
+		{
+			MethodName: "UpdateSimpleModel1",
+			Handler:    _SimpleModel1Service_UpdateSimpleModel1_Handler,
+		},
+		{
+			MethodName: "DeleteSimpleModel1",
+			Handler:    _SimpleModel1Service_DeleteSimpleModel1_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "test.proto",
+}
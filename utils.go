@@ -5,10 +5,13 @@ package pika
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
-	"reflect"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/antlr/antlr4/runtime/Go/antlr/v4"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
@@ -137,6 +140,30 @@ var (
 		parser.FilterLexerCOLON:          HintNotILike,
 	}
 
+	// Antlr comparators, in the order they are listed in errors
+	antlrComparators = []int{
+		parser.FilterLexerEQUALS,
+		parser.FilterLexerNOT_EQUALS,
+		parser.FilterLexerLESS_THAN,
+		parser.FilterLexerLESS_EQUALS,
+		parser.FilterLexerGREATER_EQUALS,
+		parser.FilterLexerGREATER_THAN,
+		parser.FilterLexerCOLON,
+	}
+
+	// Antlr value types, in the order they are listed in errors
+	antlrValueTypes = []int{
+		parser.FilterLexerSTRING,
+		parser.FilterLexerNUM_INT,
+		parser.FilterLexerNUM_UINT,
+		parser.FilterLexerNUM_FLOAT,
+		parser.FilterLexerDURATION,
+		parser.FilterLexerTIMESTAMP,
+		parser.FilterLexerTRUE,
+		parser.FilterLexerFALSE,
+		parser.FilterLexerNULL,
+	}
+
 	// Antlr values
 	antlrValues = map[int]bool{
 		parser.FilterLexerSTRING:    true,
@@ -169,6 +196,8 @@ type pikaAip160State struct {
 	activeOr         bool
 	activeExpr       *pikaFiltering
 	activeIdentifier string
+	identifierToken  antlr.Token
+	activeDot        bool
 	activeValue      any
 	activeValueType  int
 	activeOperator   string
@@ -186,19 +215,30 @@ type pikaFiltering struct {
 
 type base struct {
 	filters        []pikaFiltering
+	scopes         []pikaFiltering
+	keyset         []string
 	args           *orderedmap.OrderedMap[string, interface{}]
 	excludeColumns []string
 	includeColumns []string
+	// includeOrderBy adds the primary key and the order by columns to includeColumns when the query is built
+	includeOrderBy bool
 	orderBy        []string
+	distinct       bool
+	distinctOn     []string
+	lock           string
+	lockWait       string
 	ignoreLimit    bool
 	ignoreOffset   bool
 	ignoreOrderBy  bool
+	ignoreLock     bool
 	limit          *int
 	offset         *int
 	err            error
 	metadata       map[string]string
 	joins          []*pikaJoin
 	replaceFields  map[string]*replaceField
+	schema         string
+	updateColumns  []string
 }
 
 type pikaJoin struct {
@@ -218,7 +258,14 @@ type replaceField struct {
 }
 
 type connBase struct {
-	tableAlias map[string]string
+	tableAlias         map[string]string
+	defaultSchema      string
+	tenant             any
+	queryHooks         []QueryHook
+	logger             *slog.Logger
+	slowQueryThreshold time.Duration
+	logArgs            bool
+	stmtCache          atomic.Pointer[statementCache]
 }
 
 func newBase() *base {
@@ -263,6 +310,24 @@ func (b *base) filter(innerOr bool, or bool, queries ...string) {
 	})
 }
 
+// scope adds filters that are always combined with AND, regardless of
+// any OR filters on the query set.
+func (b *base) scope(queries ...string) {
+	newFilters := orderedmap.New[string, string]()
+	for _, query := range queries {
+		split := strings.Split(query, "=")
+		if len(split) != expectedQueryParts {
+			b.err = fmt.Errorf("%w: %s", ErrInvalidFilter, query)
+			return
+		}
+		newFilters.Set(findEmptyForKey(split[0], newFilters), split[1])
+	}
+
+	b.scopes = append(b.scopes, pikaFiltering{
+		entries: newFilters,
+	})
+}
+
 func (b *base) setArgs(args *orderedmap.OrderedMap[string, interface{}]) {
 	for pair := args.Oldest(); pair != nil; pair = pair.Next() {
 		b.args.Set(pair.Key, pair.Value)
@@ -272,9 +337,24 @@ func (b *base) setArgs(args *orderedmap.OrderedMap[string, interface{}]) {
 func (b *base) clearAll() {
 	b.args = orderedmap.New[string, interface{}]()
 	b.filters = []pikaFiltering{}
+	b.scopes = []pikaFiltering{}
 	b.joins = []*pikaJoin{}
 }
 
+// keysetArg returns the name of the argument holding the keyset value of column
+func keysetArg(column string) string {
+	return "pika_keyset_" + column
+}
+
+// setKeyset only returns rows after the given key values, in the order of columns.
+// Multiple columns are compared as a row value, so (a, b) > ($1, $2).
+func (b *base) setKeyset(columns []string, values []any) {
+	b.keyset = columns
+	for i, column := range columns {
+		b.args.Set(keysetArg(column), values[i])
+	}
+}
+
 func (b *base) setLimit(limit int) {
 	b.limit = &limit
 }
@@ -290,6 +370,37 @@ func (b *base) setOrderBy(orderBy []string, reset bool) {
 	b.orderBy = append(b.orderBy, orderBy...)
 }
 
+func (b *base) setDistinct(distinctOn []string) {
+	b.distinct = true
+	b.distinctOn = append(b.distinctOn, distinctOn...)
+}
+
+// distinctOrderBy returns the order for a DISTINCT ON query.
+// PostgreSQL requires the leftmost ORDER BY expressions to match the
+// DISTINCT ON expressions, so those columns are moved to the front.
+// If the order already contains a column, its direction is kept.
+func distinctOrderBy(distinctOn []string, orderBy []string) []string {
+	newOrderBy := make([]string, 0, len(distinctOn)+len(orderBy))
+	for _, col := range distinctOn {
+		o := col
+		for _, existing := range orderBy {
+			if strings.TrimPrefix(existing, "-") == col {
+				o = existing
+				break
+			}
+		}
+		newOrderBy = append(newOrderBy, o)
+	}
+
+	for _, o := range orderBy {
+		if !contains(distinctOn, strings.TrimPrefix(o, "-")) {
+			newOrderBy = append(newOrderBy, o)
+		}
+	}
+
+	return newOrderBy
+}
+
 func (c *connBase) TableAlias(src string, dst string) {
 	if c.tableAlias == nil {
 		c.tableAlias = make(map[string]string)
@@ -300,48 +411,38 @@ func (c *connBase) TableAlias(src string, dst string) {
 	c.tableAlias[src] = dst
 }
 
-func getPikaMetadata[T any]() map[string]string {
-	x := struct {
-		X T
-	}{}
-
-	metadata := make(map[string]string)
-
-	ref := reflect.ValueOf(x.X)
-	modelName := ref.Type().Name()
-	metadata[pikaMetadataModelName] = modelName
-
-	// Iterate through fields to get tags
-	for i := range ref.NumField() {
-		field := ref.Type().Field(i)
-
-		// We only care about fields starting with "Pika"
-		if strings.HasPrefix(field.Name, "Pika") {
-			if contains(PikaMetadataFields, field.Name) {
-				tag := field.Tag.Get("pika")
-
-				if _, ok := metadata[field.Name]; ok {
-					panic("duplicate Pika metadata field: " + field.Name)
-				}
-				metadata[field.Name] = tag
-			}
-			continue
+// jsonPathRef returns the text of a JSON path in a JSONB column, for example "t"."spec"->'container'->>'image'
+func jsonPathRef(column string, path []string) string {
+	ref := column
+	for i, key := range path {
+		operator := "->"
+		if i == len(path)-1 {
+			operator = "->>"
 		}
+		ref += fmt.Sprintf("%s'%s'", operator, strings.ReplaceAll(key, "'", "''"))
+	}
 
-		// This is a regular field, let's store information about it's type
-		tag := field.Tag.Get("db")
-		if tag == "" {
-			continue
-		}
+	return ref
+}
 
-		if _, ok := metadata[field.Name]; ok {
-			panic("duplicate Pika database field: " + field.Name)
-		}
+// quoteTableName quotes a table name, which may be qualified with a schema.
+// For example, tenant.users is quoted as "tenant"."users".
+func quoteTableName(name string) string {
+	parts := strings.SplitN(name, ".", expectedFieldParts)
+	for i, part := range parts {
+		parts[i] = fmt.Sprintf("\"%s\"", part)
+	}
+
+	return strings.Join(parts, ".")
+}
 
-		metadata[tag] = field.Type.String()
+// unqualifiedTableName returns the table name without the schema
+func unqualifiedTableName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
 	}
 
-	return metadata
+	return name
 }
 
 func contains[T comparable](slice []T, element T) bool {